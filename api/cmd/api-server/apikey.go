@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"golang-network-labs/api/internal/auth"
+	"golang-network-labs/api/internal/config"
+)
+
+// apikey 서브커맨드 사용법
+const apikeyUsage = `usage: api-server apikey create [-name NAME] [-ttl DURATION] <user_id>
+       api-server apikey list [user_id]
+       api-server apikey revoke <prefix>`
+
+// apikey 서브커맨드 실행
+func runAPIKey(args []string) error {
+	if len(args) == 0 {
+		return errors.New(apikeyUsage)
+	}
+
+	// DB 연결
+	cfg := config.Load()
+	db, err := openDB(cfg.DB)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	keys := auth.NewKeyStore(db)
+	ctx := context.Background()
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("apikey create", flag.ContinueOnError)
+		name := fs.String("name", "", "label shown in list")
+		ttl := fs.Duration("ttl", 0, "expiry, e.g. 720h (0 = never)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return errors.New(apikeyUsage)
+		}
+		key, prefix, err := keys.Create(ctx, fs.Arg(0), *name, *ttl)
+		if err != nil {
+			return err
+		}
+		// 원문은 여기서만 보임
+		fmt.Printf("prefix: %s\nkey:    %s\n(store the key now; only its hash is kept)\n", prefix, key)
+		return nil
+
+	case "list":
+		var user string
+		if len(args) > 1 {
+			user = args[1]
+		}
+		list, err := keys.List(ctx, user)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%-10s %-20s %-20s %-8s %-20s %s\n", "PREFIX", "USER", "NAME", "STATUS", "CREATED", "LAST USED")
+		for _, k := range list {
+			fmt.Printf("%-10s %-20s %-20s %-8s %-20s %s\n",
+				k.Prefix, k.UserID, k.Name, keyStatus(k), k.CreatedAt.Format(time.DateTime), fmtTime(k.LastUsedAt))
+		}
+		return nil
+
+	case "revoke":
+		if len(args) != 2 {
+			return errors.New(apikeyUsage)
+		}
+		ok, err := keys.Revoke(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("no active key with prefix %q", args[1])
+		}
+		fmt.Printf("revoked %s\n", args[1])
+		return nil
+
+	default:
+		return fmt.Errorf("unknown apikey command %q\n%s", args[0], apikeyUsage)
+	}
+}
+
+// 키 상태(active/expired/revoked)
+func keyStatus(k auth.KeyInfo) string {
+	switch {
+	case k.RevokedAt != nil:
+		return "revoked"
+	case k.ExpiresAt != nil && time.Now().After(*k.ExpiresAt):
+		return "expired"
+	default:
+		return "active"
+	}
+}
+
+// 시각 또는 "-"
+func fmtTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.DateTime)
+}
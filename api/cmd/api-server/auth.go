@@ -0,0 +1,32 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+
+	"golang-network-labs/api/internal/auth"
+	"golang-network-labs/api/internal/config"
+)
+
+// 인증기 생성(JWT 키가 없으면 API 키만)
+func newAuthenticator(cfg config.AuthConfig, db *sql.DB) (*auth.Authenticator, error) {
+	jwt, err := auth.NewJWTVerifier(auth.JWTConfig{
+		HS256Secret:        cfg.JWTHS256Secret,
+		RS256PublicKeyFile: cfg.JWTRS256PublicKeyFile,
+		Issuer:             cfg.JWTIssuer,
+		Audience:           cfg.JWTAudience,
+		Leeway:             cfg.JWTLeeway,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a := auth.NewAuthenticator(cfg.Mode, auth.NewKeyStore(db), jwt)
+	switch a.Mode() {
+	case auth.ModeOff:
+		log.Println("auth: AUTH_MODE=off, X-User-Id is trusted as is (development only)")
+	default:
+		log.Printf("auth: mode=%s api_keys=on jwt=%t", a.Mode(), jwt != nil)
+	}
+	return a, nil
+}
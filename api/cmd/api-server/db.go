@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"golang-network-labs/api/internal/config"
+)
+
+// MariaDB 연결 재시도 횟수
+const dbConnectRetries = 30
+
+// MariaDB 연결
+func openDB(cfg config.DBConfig) (*sql.DB, error) {
+	// DSN 구성
+	mc := mysql.NewConfig()
+	mc.User = cfg.User
+	mc.Passwd = cfg.Pass
+	mc.Net = "tcp"
+	mc.Addr = net.JoinHostPort(cfg.Host, cfg.Port)
+	mc.DBName = cfg.Name
+	// DATETIME → time.Time
+	mc.ParseTime = true
+	mc.Loc = time.Local
+
+	// 핸들 생성
+	db, err := sql.Open("mysql", mc.FormatDSN())
+	if err != nil {
+		return nil, err
+	}
+
+	// 커넥션 풀 설정
+	db.SetMaxOpenConns(20)
+	db.SetMaxIdleConns(10)
+	db.SetConnMaxLifetime(5 * time.Minute)
+
+	// 컨테이너 기동 순서 대비 재시도
+	for i := 1; ; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		err = db.PingContext(ctx)
+		cancel()
+		if err == nil {
+			return db, nil
+		}
+		// 재시도 초과면 실패
+		if i >= dbConnectRetries {
+			_ = db.Close()
+			return nil, err
+		}
+		log.Printf("db not ready (%d/%d): %v", i, dbConnectRetries, err)
+		time.Sleep(1 * time.Second)
+	}
+}
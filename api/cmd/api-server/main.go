@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"golang-network-labs/api/internal/config"
+	"golang-network-labs/api/internal/handler"
+	"golang-network-labs/api/internal/jobs"
+	"golang-network-labs/api/internal/migrate"
+	"golang-network-labs/api/internal/tcpclient"
+)
+
+func main() {
+	// migrate 서브커맨드
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// apikey 서브커맨드
+	if len(os.Args) > 1 && os.Args[1] == "apikey" {
+		if err := runAPIKey(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// 실행 실패면 종료
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// API 서버 실행
+func run() error {
+	// 환경변수 로드
+	cfg := config.Load()
+
+	// DB 연결
+	db, err := openDB(cfg.DB)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// 스키마 마이그레이션(dirty/새 스키마면 기동 거절)
+	m, err := migrate.New(db)
+	if err != nil {
+		return err
+	}
+	if err := migrateOnStart(context.Background(), m); err != nil {
+		return err
+	}
+
+	// TCP 클라이언트 생성
+	tcp, err := tcpclient.New(tcpclient.Config{
+		Host:           cfg.TCP.Host,
+		Port:           cfg.TCP.Port,
+		DialTimeout:    cfg.TCP.DialTimeout,
+		IOTimeout:      cfg.TCP.IOTimeout,
+		MaxConns:       cfg.TCP.PoolSize,
+		IdleTimeout:    cfg.TCP.IdleTimeout,
+		HealthInterval: cfg.TCP.HealthInterval,
+		Framing:        cfg.TCP.Framing,
+		MaxFrame:       cfg.TCP.MaxFrame,
+		TLSCAFile:      cfg.TCP.TLSCAFile,
+		TLSCertFile:    cfg.TCP.TLSCertFile,
+		TLSKeyFile:     cfg.TCP.TLSKeyFile,
+		TLSServerName:  cfg.TCP.TLSServerName,
+		HMACKeyID:      cfg.TCP.HMACKeyID,
+		HMACSecret:     cfg.TCP.HMACSecret,
+	})
+	if err != nil {
+		return err
+	}
+	defer tcp.Close()
+
+	// 비동기 작업(이전 프로세스가 남긴 작업 정리)
+	jm := jobs.New(db, tcp, jobs.Config{
+		MaxConcurrency: cfg.Jobs.MaxConcurrency,
+		MaxQueued:      cfg.Jobs.MaxQueued,
+		DefaultTimeout: cfg.Jobs.DefaultTimeout,
+		LeaseTTL:       cfg.Jobs.LeaseTTL,
+	})
+	if err := jm.Recover(context.Background()); err != nil {
+		return err
+	}
+
+	// 핸들러 생성
+	h := handler.New(handler.Deps{DB: db, TCP: tcp, Jobs: jm})
+
+	// 인증(API 키 + JWT)
+	authn, err := newAuthenticator(cfg.Auth, db)
+	if err != nil {
+		return err
+	}
+
+	// 공개 API 서버
+	public := &http.Server{
+		Addr:              ":" + cfg.HTTP.Port,
+		Handler:           newPublicRouter(cfg, h, authn),
+		ReadHeaderTimeout: cfg.HTTP.Timeout,
+		ReadTimeout:       cfg.HTTP.Timeout,
+		IdleTimeout:       60 * time.Second,
+	}
+
+	// 관리용 서버
+	admin := &http.Server{
+		Addr:              ":" + cfg.HTTP.AdminPort,
+		Handler:           newAdminRouter(cfg, h, authn),
+		ReadHeaderTimeout: cfg.HTTP.Timeout,
+	}
+
+	// 서버 에러 수집
+	errCh := make(chan error, 2)
+	for _, srv := range []*http.Server{public, admin} {
+		go func(srv *http.Server) {
+			log.Println("http :", srv.Addr)
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errCh <- err
+			}
+		}(srv)
+	}
+
+	// 종료 시그널 대기
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var serveErr error
+	select {
+	case serveErr = <-errCh:
+	case <-ctx.Done():
+		log.Println("shutdown signal received")
+	}
+
+	// 처리중 요청 정리
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_ = public.Shutdown(shutdownCtx)
+	_ = admin.Shutdown(shutdownCtx)
+	// 실행중 작업 대기(기한 넘으면 중단, 대기중 작업은 다음 기동 때 실행)
+	jm.Close(shutdownCtx)
+
+	return serveErr
+}
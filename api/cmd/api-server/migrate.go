@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"golang-network-labs/api/internal/config"
+	"golang-network-labs/api/internal/migrate"
+)
+
+// migrate 서브커맨드 사용법
+const migrateUsage = "usage: api-server migrate status|up|down [steps]"
+
+// migrate 서브커맨드 실행
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	// down 단계 수(기본 1)
+	steps := 1
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid steps %q\n%s", args[1], migrateUsage)
+		}
+		steps = n
+	}
+
+	// DB 연결
+	cfg := config.Load()
+	db, err := openDB(cfg.DB)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	m, err := migrate.New(db)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "status":
+		st, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("current: %d\nlatest:  %d\ndirty:   %t\n", st.Current, st.Latest, st.Dirty)
+		for _, p := range st.Pending {
+			fmt.Printf("pending: %04d_%s\n", p.Version, p.Name)
+		}
+		return nil
+
+	case "up":
+		n, err := m.Up(ctx)
+		fmt.Printf("applied %d migration(s)\n", n)
+		return err
+
+	case "down":
+		n, err := m.Down(ctx, steps)
+		fmt.Printf("reverted %d migration(s)\n", n)
+		return err
+
+	default:
+		return fmt.Errorf("unknown migrate command %q\n%s", args[0], migrateUsage)
+	}
+}
+
+// 기동 시 스키마 최신화
+func migrateOnStart(ctx context.Context, m *migrate.Migrator) error {
+	n, err := m.Up(ctx)
+	if err != nil {
+		return fmt.Errorf("schema migration failed: %w", err)
+	}
+	if n > 0 {
+		fmt.Printf("applied %d migration(s), schema version %d\n", n, m.Latest())
+	}
+	return nil
+}
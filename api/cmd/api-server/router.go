@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"golang-network-labs/api/internal/auth"
+	"golang-network-labs/api/internal/config"
+	"golang-network-labs/api/internal/handler"
+	"golang-network-labs/api/internal/middleware"
+)
+
+// 공개 API 라우터
+func newPublicRouter(cfg config.Config, h *handler.Handler, authn *auth.Authenticator) http.Handler {
+	r := chi.NewRouter()
+
+	// 전체 요청 로깅
+	r.Use(middleware.RequestLogger())
+	// 인증 전 IP별 레이트리밋(자격 증명 대입/키 조회 폭주 방지)
+	r.Use(middleware.RateLimitPerIP(cfg.Rate.IPRPS, cfg.Rate.IPBurst, cfg.Rate.TrustedProxies))
+	// 인증(이후 핸들러/레이트리밋은 context의 Principal 사용)
+	r.Use(authn.Middleware())
+
+	// 호출자별 레이트리밋(라우트 공통 상태)
+	rate := middleware.RateLimitPerClient(cfg.Rate.RPS, cfg.Rate.Burst, cfg.Rate.TrustedProxies)
+	// /run 동시 실행 제한(GET/POST 공통 슬롯)
+	conc := middleware.ConcurrencyLimit(cfg.Run.MaxConcurrency)
+
+	// /run: 레이트리밋 + 동시 실행 제한
+	r.With(rate, conc).Get("/run", h.Run)
+	r.With(rate, conc).Post("/run", h.Run)
+
+	// /jobs: 등록은 바로 반환하므로 레이트리밋만
+	r.With(rate).Post("/jobs", h.CreateJob)
+	r.With(rate).Get("/jobs/{id}", h.GetJob)
+	r.With(rate).Delete("/jobs/{id}", h.CancelJob)
+
+	// /file, /files, /title: 레이트리밋만(업로드 본문 제한시간은 핸들러가 청크마다 연장)
+	r.With(rate).Get("/file", h.File)
+	r.With(rate).Get("/files", h.Files)
+	r.With(rate).Put("/files", h.PutFile)
+	r.With(rate).Get("/files/stat", h.FileStat)
+	r.With(rate).Get("/files/hash", h.FileHash)
+	r.With(rate).Get("/files/download", h.Download)
+	r.With(rate).Head("/files/download", h.Download)
+	r.With(rate).Get("/title", h.Title)
+
+	return r
+}
+
+// 관리용 라우터(healthz/metrics는 프로브용으로 인증 없음)
+func newAdminRouter(cfg config.Config, h *handler.Handler, authn *auth.Authenticator) http.Handler {
+	r := chi.NewRouter()
+
+	r.Get("/healthz", h.Healthz)
+	r.Get("/metrics", h.Metrics)
+	// 정책 조회는 공개 API와 같은 인증 후 호출자 ID로 전달
+	r.With(middleware.RateLimitPerIP(cfg.Rate.IPRPS, cfg.Rate.IPBurst, cfg.Rate.TrustedProxies), authn.Middleware()).Get("/policy", h.Policy)
+
+	return r
+}
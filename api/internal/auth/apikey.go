@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+// API 키 형식: gnl_<prefix 8자>_<secret 64자>
+const (
+	keyScheme    = "gnl_"
+	prefixBytes  = 4
+	secretBytes  = 32
+	maxKeyLength = 128
+)
+
+// API 키 검증 실패
+var (
+	ErrInvalidKey = errors.New("invalid api key")
+	ErrKeyExpired = errors.New("api key expired")
+	ErrKeyRevoked = errors.New("api key revoked")
+)
+
+// 키 목록 항목(해시/원문 제외)
+type KeyInfo struct {
+	Prefix     string
+	UserID     string
+	Name       string
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	RevokedAt  *time.Time
+	LastUsedAt *time.Time
+}
+
+// API 키 저장소(MariaDB, 원문은 저장하지 않음)
+type KeyStore struct {
+	db *sql.DB
+}
+
+// 저장소 생성
+func NewKeyStore(db *sql.DB) *KeyStore {
+	return &KeyStore{db: db}
+}
+
+// 키 원문 → 저장용 해시(키 자체가 256비트 난수라 느린 해시 불필요)
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// 난수 hex
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// 키 발급(원문은 이때 한 번만 반환)
+func (s *KeyStore) Create(ctx context.Context, userID, name string, ttl time.Duration) (key, prefix string, err error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" || userID == Anonymous {
+		return "", "", errors.New("user id required")
+	}
+
+	prefix, err = randomHex(prefixBytes)
+	if err != nil {
+		return "", "", err
+	}
+	secret, err := randomHex(secretBytes)
+	if err != nil {
+		return "", "", err
+	}
+	key = keyScheme + prefix + "_" + secret
+
+	// 만료(0이면 없음)
+	now := time.Now()
+	var expires any
+	if ttl > 0 {
+		expires = now.Add(ttl)
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO api_keys(prefix, key_hash, user_id, name, created_at, expires_at)
+		 VALUES (?,?,?,?,?,?)`,
+		prefix, hashKey(key), userID, strings.TrimSpace(name), now, expires,
+	)
+	if err != nil {
+		return "", "", err
+	}
+	return key, prefix, nil
+}
+
+// 키 검증 → Principal
+func (s *KeyStore) Lookup(ctx context.Context, key string) (Principal, error) {
+	// 형식이 다르면 DB 조회 없이 거절
+	key = strings.TrimSpace(key)
+	if len(key) > maxKeyLength || !strings.HasPrefix(key, keyScheme) {
+		return Principal{}, ErrInvalidKey
+	}
+
+	var (
+		prefix, userID   string
+		expires, revoked sql.NullTime
+	)
+	err := s.db.QueryRowContext(ctx,
+		`SELECT prefix, user_id, expires_at, revoked_at FROM api_keys WHERE key_hash=?`,
+		hashKey(key),
+	).Scan(&prefix, &userID, &expires, &revoked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Principal{}, ErrInvalidKey
+	}
+	if err != nil {
+		return Principal{}, err
+	}
+	if revoked.Valid {
+		return Principal{}, ErrKeyRevoked
+	}
+	if expires.Valid && time.Now().After(expires.Time) {
+		return Principal{}, ErrKeyExpired
+	}
+
+	// 마지막 사용 시각(분 단위로만 갱신, 실패는 무시)
+	_, _ = s.db.ExecContext(ctx,
+		`UPDATE api_keys SET last_used_at=?
+		 WHERE prefix=? AND (last_used_at IS NULL OR last_used_at < ?)`,
+		time.Now(), prefix, time.Now().Add(-time.Minute),
+	)
+
+	return Principal{ID: userID, Method: MethodAPIKey, KeyID: prefix}, nil
+}
+
+// 키 폐기(없거나 이미 폐기면 false)
+func (s *KeyStore) Revoke(ctx context.Context, prefix string) (bool, error) {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at=? WHERE prefix=? AND revoked_at IS NULL`,
+		time.Now(), strings.TrimSpace(prefix),
+	)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// 키 목록(userID가 비면 전체)
+func (s *KeyStore) List(ctx context.Context, userID string) ([]KeyInfo, error) {
+	q := `SELECT prefix, user_id, name, created_at, expires_at, revoked_at, last_used_at FROM api_keys`
+	var args []any
+	if userID = strings.TrimSpace(userID); userID != "" {
+		q += ` WHERE user_id=?`
+		args = append(args, userID)
+	}
+	q += ` ORDER BY created_at`
+
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []KeyInfo
+	for rows.Next() {
+		var (
+			k                          KeyInfo
+			expires, revoked, lastUsed sql.NullTime
+		)
+		if err := rows.Scan(&k.Prefix, &k.UserID, &k.Name, &k.CreatedAt, &expires, &revoked, &lastUsed); err != nil {
+			return nil, err
+		}
+		k.ExpiresAt = timePtr(expires)
+		k.RevokedAt = timePtr(revoked)
+		k.LastUsedAt = timePtr(lastUsed)
+		out = append(out, k)
+	}
+	return out, rows.Err()
+}
+
+// NULL 시각 → 포인터
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,246 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// 토큰 최대 길이
+const maxTokenLength = 8 << 10
+
+// 사용자 ID 최대 길이(logs/jobs user_id 컬럼)
+const maxSubjectLength = 128
+
+// JWT 검증 실패
+var ErrInvalidToken = errors.New("invalid token")
+
+// JWT 검증 설정
+type JWTConfig struct {
+	// HS256 공유 비밀키(비면 HS256 거절)
+	HS256Secret string
+	// RS256 공개키 PEM 파일(비면 RS256 거절)
+	RS256PublicKeyFile string
+	// iss 일치 요구(비면 검사 안 함)
+	Issuer string
+	// aud 포함 요구(비면 검사 안 함)
+	Audience string
+	// exp/nbf 시각 오차 허용
+	Leeway time.Duration
+}
+
+// JWT 검증기
+type JWTVerifier struct {
+	hsKey    []byte
+	rsKey    *rsa.PublicKey
+	issuer   string
+	audience string
+	leeway   time.Duration
+}
+
+// 검증기 생성(키가 하나도 없으면 nil → JWT 사용 안 함)
+func NewJWTVerifier(cfg JWTConfig) (*JWTVerifier, error) {
+	v := &JWTVerifier{
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		leeway:   cfg.Leeway,
+	}
+	if cfg.HS256Secret != "" {
+		v.hsKey = []byte(cfg.HS256Secret)
+	}
+	if cfg.RS256PublicKeyFile != "" {
+		key, err := loadRSAPublicKey(cfg.RS256PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		v.rsKey = key
+	}
+	if v.hsKey == nil && v.rsKey == nil {
+		return nil, nil
+	}
+	return v, nil
+}
+
+// PEM 공개키 읽기(PKIX/PKCS1/인증서)
+func loadRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("jwt public key: %w", err)
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("jwt public key %s: no PEM block", path)
+	}
+
+	var pub any
+	switch block.Type {
+	case "PUBLIC KEY":
+		pub, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		pub, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		cert, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			pub = cert.PublicKey
+		}
+	default:
+		return nil, fmt.Errorf("jwt public key %s: unsupported PEM type %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("jwt public key %s: %w", path, err)
+	}
+
+	key, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("jwt public key %s: not an RSA key", path)
+	}
+	return key, nil
+}
+
+// JWT 헤더
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+// 사용하는 클레임
+type jwtClaims struct {
+	Sub string   `json:"sub"`
+	Iss string   `json:"iss"`
+	Aud audience `json:"aud"`
+	Exp *float64 `json:"exp"`
+	Nbf *float64 `json:"nbf"`
+	Jti string   `json:"jti"`
+}
+
+// aud(문자열 또는 배열)
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*a = audience{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// 토큰 검증 → Principal
+func (v *JWTVerifier) Verify(token string) (Principal, error) {
+	if len(token) > maxTokenLength {
+		return Principal{}, fmt.Errorf("%w: too long", ErrInvalidToken)
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Principal{}, fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+
+	// 헤더
+	var hdr jwtHeader
+	if err := decodeSegment(parts[0], &hdr); err != nil {
+		return Principal{}, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+
+	// 서명(설정된 키의 알고리즘만 허용 → alg 혼동/none 차단)
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: signature encoding", ErrInvalidToken)
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	switch {
+	case hdr.Alg == "HS256" && v.hsKey != nil:
+		mac := hmac.New(sha256.New, v.hsKey)
+		mac.Write(signed)
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return Principal{}, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+	case hdr.Alg == "RS256" && v.rsKey != nil:
+		sum := sha256.Sum256(signed)
+		if err := rsa.VerifyPKCS1v15(v.rsKey, crypto.SHA256, sum[:], sig); err != nil {
+			return Principal{}, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+	default:
+		return Principal{}, fmt.Errorf("%w: unsupported alg %q", ErrInvalidToken, hdr.Alg)
+	}
+
+	// 클레임
+	var c jwtClaims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return Principal{}, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+	if err := v.checkClaims(c); err != nil {
+		return Principal{}, err
+	}
+
+	return Principal{ID: c.Sub, Method: MethodJWT, KeyID: c.Jti}, nil
+}
+
+// 시각/발급자/대상/주체 검사
+func (v *JWTVerifier) checkClaims(c jwtClaims) error {
+	now := time.Now()
+
+	// exp 필수
+	if c.Exp == nil {
+		return fmt.Errorf("%w: exp required", ErrInvalidToken)
+	}
+	if now.After(unixTime(*c.Exp).Add(v.leeway)) {
+		return fmt.Errorf("%w: expired", ErrInvalidToken)
+	}
+	if c.Nbf != nil && now.Add(v.leeway).Before(unixTime(*c.Nbf)) {
+		return fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	}
+
+	if v.issuer != "" && c.Iss != v.issuer {
+		return fmt.Errorf("%w: issuer mismatch", ErrInvalidToken)
+	}
+	if v.audience != "" && !contains(c.Aud, v.audience) {
+		return fmt.Errorf("%w: audience mismatch", ErrInvalidToken)
+	}
+
+	// 앞뒤 공백/익명/과도한 길이 거절
+	sub := c.Sub
+	if sub == "" || sub != strings.TrimSpace(sub) || sub == Anonymous || len(sub) > maxSubjectLength {
+		return fmt.Errorf("%w: invalid sub", ErrInvalidToken)
+	}
+	return nil
+}
+
+// base64url JSON 조각 디코딩
+func decodeSegment(seg string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// NumericDate → time
+func unixTime(sec float64) time.Time {
+	return time.Unix(0, int64(sec*float64(time.Second)))
+}
+
+// 목록 포함 여부
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"golang-network-labs/api/internal/problem"
+)
+
+// 인증 모드
+const (
+	// 자격 증명 필수(기본)
+	ModeRequired = "required"
+	// 있으면 검증, 없으면 anonymous
+	ModeOptional = "optional"
+	// X-User-Id 그대로 신뢰(개발용)
+	ModeOff = "off"
+)
+
+// API 키 헤더
+const apiKeyHeader = "X-API-Key"
+
+// 자격 증명 문제(401)
+var (
+	ErrNoCredentials  = errors.New("credentials required")
+	ErrBearerDisabled = errors.New("bearer tokens are not accepted")
+)
+
+// 요청 인증기
+type Authenticator struct {
+	mode string
+	keys *KeyStore
+	// nil이면 Bearer 토큰 거절
+	jwt *JWTVerifier
+}
+
+// 인증기 생성(모르는 모드는 required)
+func NewAuthenticator(mode string, keys *KeyStore, jwt *JWTVerifier) *Authenticator {
+	switch mode {
+	case ModeOptional, ModeOff:
+	default:
+		mode = ModeRequired
+	}
+	return &Authenticator{mode: mode, keys: keys, jwt: jwt}
+}
+
+// 적용 모드
+func (a *Authenticator) Mode() string { return a.mode }
+
+// 인증 미들웨어(성공하면 context에 Principal)
+func (a *Authenticator) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, err := a.authenticate(r)
+			if err != nil {
+				// 저장소 장애는 401이 아닌 503
+				if !isCredentialError(err) {
+					log.Printf("auth: %v", err)
+					problem.Error(w, r, problem.CodeAuthUnavailable, "authentication unavailable")
+					return
+				}
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+				problem.Error(w, r, problem.CodeUnauthenticated, err.Error())
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), p)))
+		})
+	}
+}
+
+// 요청 → Principal
+func (a *Authenticator) authenticate(r *http.Request) (Principal, error) {
+	// 개발용: 헤더 그대로
+	if a.mode == ModeOff {
+		id := strings.TrimSpace(r.Header.Get("X-User-Id"))
+		if id == "" {
+			id = Anonymous
+		}
+		return Principal{ID: id, Method: MethodHeader}, nil
+	}
+
+	// API 키
+	if key := strings.TrimSpace(r.Header.Get(apiKeyHeader)); key != "" {
+		return a.keys.Lookup(r.Context(), key)
+	}
+
+	// Bearer JWT
+	if h := r.Header.Get("Authorization"); h != "" {
+		scheme, token, ok := strings.Cut(h, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			return Principal{}, ErrInvalidToken
+		}
+		if a.jwt == nil {
+			return Principal{}, ErrBearerDisabled
+		}
+		return a.jwt.Verify(strings.TrimSpace(token))
+	}
+
+	// 자격 증명 없음
+	if a.mode == ModeOptional {
+		return Principal{ID: Anonymous, Method: MethodAnonymous}, nil
+	}
+	return Principal{}, ErrNoCredentials
+}
+
+// 401 대상 에러(그 밖은 DB 장애 등)
+func isCredentialError(err error) bool {
+	for _, target := range []error{ErrNoCredentials, ErrBearerDisabled, ErrInvalidKey, ErrKeyExpired, ErrKeyRevoked, ErrInvalidToken} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
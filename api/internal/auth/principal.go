@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+)
+
+// 인증 방식
+const (
+	// X-API-Key
+	MethodAPIKey = "api_key"
+	// Authorization: Bearer <JWT>
+	MethodJWT = "jwt"
+	// AUTH_MODE=off: X-User-Id 그대로(개발용)
+	MethodHeader = "header"
+	// AUTH_MODE=optional: 자격 증명 없음
+	MethodAnonymous = "anonymous"
+)
+
+// 익명 사용자 ID
+const Anonymous = "anonymous"
+
+// 인증된 호출자
+type Principal struct {
+	// 사용자 ID(TCP 요청의 user_id)
+	ID string
+	// 인증 방식
+	Method string
+	// API 키 접두사 또는 JWT jti(로그용)
+	KeyID string
+}
+
+// context 키
+type ctxKey struct{}
+
+// Principal을 담은 context
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+// context의 Principal(없으면 false)
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(ctxKey{}).(Principal)
+	return p, ok
+}
+
+// context의 사용자 ID(없으면 anonymous)
+func UserID(ctx context.Context) string {
+	if p, ok := FromContext(ctx); ok && p.ID != "" {
+		return p.ID
+	}
+	return Anonymous
+}
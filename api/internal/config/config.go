@@ -1,6 +1,8 @@
 package config
 
 import (
+	"log"
+	"net/netip"
 	"os"
 	"strconv"
 	"strings"
@@ -13,7 +15,9 @@ type Config struct {
 	TCP  TCPConfig
 	HTTP HTTPConfig
 	Run  RunConfig
+	Jobs JobsConfig
 	Rate RateConfig
+	Auth AuthConfig
 }
 
 // DB 설정
@@ -31,11 +35,33 @@ type TCPConfig struct {
 	Port        string
 	DialTimeout time.Duration
 	IOTimeout   time.Duration
+	// 연결 풀 크기
+	PoolSize int
+	// 유휴 연결 정리 기준
+	IdleTimeout time.Duration
+	// 헬스체크 주기
+	HealthInterval time.Duration
+	// 전송 방식(frame/line)
+	Framing string
+	// 메시지 최대 크기
+	MaxFrame int
+	// TLS(CA 설정 시 사용)
+	TLSCAFile     string
+	TLSCertFile   string
+	TLSKeyFile    string
+	TLSServerName string
+	// 요청 서명 키
+	HMACKeyID  string
+	HMACSecret string
 }
 
 // HTTP 설정
 type HTTPConfig struct {
 	Timeout time.Duration
+	// 공개 API 포트
+	Port string
+	// 관리용(healthz/metrics) 포트
+	AdminPort string
 }
 
 // /run 동시 실행 제한
@@ -43,10 +69,42 @@ type RunConfig struct {
 	MaxConcurrency int
 }
 
-// IP RateLimit 설정
+// 비동기 작업 설정
+type JobsConfig struct {
+	// 동시에 실행할 작업 수
+	MaxConcurrency int
+	// 실행 슬롯을 기다릴 수 있는 작업 수
+	MaxQueued int
+	// timeout_ms가 없을 때 실행 제한시간
+	DefaultTimeout time.Duration
+	// 작업 소유 기간(죽은 인스턴스의 작업 정리 기준)
+	LeaseTTL time.Duration
+}
+
+// RateLimit 설정
 type RateConfig struct {
+	// 호출자(사용자, 익명은 IP)별
 	RPS   float64
 	Burst int
+	// 인증 전 IP별
+	IPRPS   float64
+	IPBurst int
+	// X-Forwarded-For를 믿을 프록시 대역(비면 RemoteAddr만 사용)
+	TrustedProxies []netip.Prefix
+}
+
+// API 인증 설정
+type AuthConfig struct {
+	// required/optional/off
+	Mode string
+	// JWT: HS256 비밀키, RS256 공개키 파일
+	JWTHS256Secret        string
+	JWTRS256PublicKeyFile string
+	// JWT: iss/aud 검사(비면 생략)
+	JWTIssuer   string
+	JWTAudience string
+	// JWT: exp/nbf 시각 오차
+	JWTLeeway time.Duration
 }
 
 // 초 단위 환경변수 → Duration
@@ -101,6 +159,34 @@ func envFloat(key string, def float64) float64 {
 	return f
 }
 
+// 대역 목록 환경변수("10.0.0.0/8,192.168.1.5", 잘못된 항목은 경고 후 제외)
+func envPrefixes(key string) []netip.Prefix {
+	var out []netip.Prefix
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		// 단일 IP는 /32, /128
+		if !strings.Contains(v, "/") {
+			addr, err := netip.ParseAddr(v)
+			if err != nil {
+				log.Printf("%s: ignoring %q: %v", key, v, err)
+				continue
+			}
+			out = append(out, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		p, err := netip.ParsePrefix(v)
+		if err != nil {
+			log.Printf("%s: ignoring %q: %v", key, v, err)
+			continue
+		}
+		out = append(out, p.Masked())
+	}
+	return out
+}
+
 // 환경변수 로드
 func Load() Config {
 	dbHost := strings.TrimSpace(os.Getenv("DB_HOST"))
@@ -123,17 +209,55 @@ func Load() Config {
 		tcpPort = "9000"
 	}
 
+	// HTTP 포트
+	httpPort := strings.TrimSpace(os.Getenv("HTTP_PORT"))
+	adminPort := strings.TrimSpace(os.Getenv("ADMIN_PORT"))
+	if httpPort == "" {
+		httpPort = "8080"
+	}
+	if adminPort == "" {
+		adminPort = "8081"
+	}
+
 	// Timeout
 	httpTimeout := envSeconds("HTTP_TIMEOUT_SEC", 5)
 	dialTimeout := envSeconds("TCP_DIAL_TIMEOUT_SEC", 2)
 	ioTimeout := envSeconds("TCP_IO_TIMEOUT_SEC", 5)
 
+	// TCP 연결 풀
+	poolSize := envInt("TCP_POOL_SIZE", 4)
+	idleTimeout := envSeconds("TCP_IDLE_TIMEOUT_SEC", 60)
+	healthInterval := envSeconds("TCP_HEALTH_INTERVAL_SEC", 15)
+
+	// TCP 전송 방식
+	framing := strings.ToLower(strings.TrimSpace(os.Getenv("TCP_FRAMING")))
+	if framing != "line" {
+		framing = "frame"
+	}
+	maxFrame := envInt("TCP_MAX_FRAME_BYTES", 4<<20)
+
 	// /run 동시 실행 제한 (기본 5)
 	maxConc := envInt("RUN_MAX_CONCURRENCY", 5)
 
+	// 비동기 작업 기본값
+	jobConc := envInt("JOB_MAX_CONCURRENCY", 4)
+	jobQueued := envInt("JOB_MAX_QUEUED", 100)
+	jobTimeout := envSeconds("JOB_DEFAULT_TIMEOUT_SEC", 60)
+	jobLease := envSeconds("JOB_LEASE_SEC", 30)
+
 	// IP 레이트리밋 기본값
 	rps := envFloat("RATE_RPS", 5)
 	burst := envInt("RATE_BURST", 10)
+	ipRPS := envFloat("RATE_IP_RPS", 20)
+	ipBurst := envInt("RATE_IP_BURST", 40)
+	// 클라이언트 IP를 X-Forwarded-For에서 읽을 프록시
+	trusted := envPrefixes("TRUSTED_PROXIES")
+
+	// 인증 모드(기본 required)
+	authMode := strings.ToLower(strings.TrimSpace(os.Getenv("AUTH_MODE")))
+	if authMode == "" {
+		authMode = "required"
+	}
 
 	// 설정 묶어서 반환
 	return Config{
@@ -145,20 +269,50 @@ func Load() Config {
 			Pass: dbPass,
 		},
 		TCP: TCPConfig{
-			Host:        tcpHost,
-			Port:        tcpPort,
-			DialTimeout: dialTimeout,
-			IOTimeout:   ioTimeout,
+			Host:           tcpHost,
+			Port:           tcpPort,
+			DialTimeout:    dialTimeout,
+			IOTimeout:      ioTimeout,
+			PoolSize:       poolSize,
+			IdleTimeout:    idleTimeout,
+			HealthInterval: healthInterval,
+			Framing:        framing,
+			MaxFrame:       maxFrame,
+			TLSCAFile:      strings.TrimSpace(os.Getenv("TCP_TLS_CA_FILE")),
+			TLSCertFile:    strings.TrimSpace(os.Getenv("TCP_TLS_CERT_FILE")),
+			TLSKeyFile:     strings.TrimSpace(os.Getenv("TCP_TLS_KEY_FILE")),
+			TLSServerName:  strings.TrimSpace(os.Getenv("TCP_TLS_SERVER_NAME")),
+			HMACKeyID:      strings.TrimSpace(os.Getenv("TCP_HMAC_KEY_ID")),
+			HMACSecret:     strings.TrimSpace(os.Getenv("TCP_HMAC_SECRET")),
 		},
 		HTTP: HTTPConfig{
-			Timeout: httpTimeout,
+			Timeout:   httpTimeout,
+			Port:      httpPort,
+			AdminPort: adminPort,
 		},
 		Run: RunConfig{
 			MaxConcurrency: maxConc,
 		},
+		Jobs: JobsConfig{
+			MaxConcurrency: jobConc,
+			MaxQueued:      jobQueued,
+			DefaultTimeout: jobTimeout,
+			LeaseTTL:       jobLease,
+		},
 		Rate: RateConfig{
-			RPS:   rps,
-			Burst: burst,
+			RPS:            rps,
+			Burst:          burst,
+			IPRPS:          ipRPS,
+			IPBurst:        ipBurst,
+			TrustedProxies: trusted,
+		},
+		Auth: AuthConfig{
+			Mode:                  authMode,
+			JWTHS256Secret:        strings.TrimSpace(os.Getenv("AUTH_JWT_HS256_SECRET")),
+			JWTRS256PublicKeyFile: strings.TrimSpace(os.Getenv("AUTH_JWT_RS256_PUBLIC_KEY_FILE")),
+			JWTIssuer:             strings.TrimSpace(os.Getenv("AUTH_JWT_ISSUER")),
+			JWTAudience:           strings.TrimSpace(os.Getenv("AUTH_JWT_AUDIENCE")),
+			JWTLeeway:             envSeconds("AUTH_JWT_LEEWAY_SEC", 30),
 		},
 	}
 }
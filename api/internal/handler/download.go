@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"golang-network-labs/api/internal/auth"
+	"golang-network-labs/api/internal/problem"
+	"golang-network-labs/api/internal/tcpclient"
+)
+
+// 다운로드 청크 크기(TCP 서버 file limit 상한)
+const downloadChunk = 1 << 20
+
+// Content-Type 판별에 쓰는 앞부분 크기
+const sniffLen = 512
+
+// /files/download: TCP에서 청크를 당겨 파일 전체를 원본 바이트로 전송
+// - Range/If-Range/If-None-Match는 http.ServeContent가 처리
+func (h *Handler) Download(w http.ResponseWriter, r *http.Request) {
+	// inFlight 증가
+	incInFlight()
+	// 종료 시 감소
+	defer decInFlight()
+
+	userID := auth.UserID(r.Context())
+	reqID := newRequestID()
+
+	p := strings.TrimSpace(r.URL.Query().Get("path"))
+	if p == "" {
+		problem.Error(w, r, tcpclient.CodeBadRequest, "path required")
+		return
+	}
+
+	// 크기/수정 시각(ETag 기준)
+	st := h.tcp.Call(r.Context(), tcpclient.Req{
+		RequestID: reqID,
+		UserID:    userID,
+		Type:      "stat",
+		Path:      p,
+	})
+	if !st.Ok {
+		writeTCPProblem(w, r, st)
+		return
+	}
+	if st.Entry == nil {
+		st.Code = tcpclient.CodeIncompatible
+		st.Error = "tcp server returned no file entry"
+		writeTCPProblem(w, r, st)
+		return
+	}
+	if st.Entry.Type != "file" {
+		problem.Error(w, r, tcpclient.CodeBadRequest, "not a regular file")
+		return
+	}
+
+	f := &remoteFile{
+		ctx:    r.Context(),
+		tcp:    h.tcp,
+		reqID:  reqID,
+		userID: userID,
+		path:   p,
+		size:   st.Entry.Size,
+	}
+
+	// 검증자(크기+수정 시각)
+	w.Header().Set("ETag", fileETag(st.Entry.Size, st.Entry.ModTime))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": st.Entry.Name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	// Content-Type: 확장자 → 앞부분 내용(HEAD/304도 같은 값)
+	ctype := mime.TypeByExtension(path.Ext(st.Entry.Name))
+	if ctype == "" {
+		head, err := f.peek(sniffLen)
+		if err != nil {
+			f.writeProblem(w, r)
+			return
+		}
+		ctype = http.DetectContentType(head)
+	}
+	w.Header().Set("Content-Type", ctype)
+
+	http.ServeContent(w, r, st.Entry.Name, st.Entry.ModTime, f)
+
+	// 중간 실패는 이미 헤더가 나갔으므로 로그만(연결은 net/http가 끊음)
+	if f.failed != nil {
+		log.Printf("download request_id=%s path=%s: %s", reqID, p, f.failed.Error)
+	}
+
+	// file_reads 로그(실제로 가져온 범위)
+	if f.fetched > 0 || f.failed != nil {
+		errMsg := ""
+		if f.failed != nil {
+			errMsg = f.failed.Error
+		}
+		_, _ = h.db.Exec(
+			`INSERT INTO file_reads(ts, request_id, user_id, file_path, file_offset, limit_size, ok, err_msg)
+			 VALUES (?,?,?,?,?,?,?,?)`,
+			now(), reqID, userID, p, f.firstOffset, f.fetched, boolToInt(f.failed == nil), nullableErr(errMsg),
+		)
+	}
+}
+
+// 크기+수정 시각 기반 ETag
+func fileETag(size int64, mod time.Time) string {
+	return fmt.Sprintf(`"%x-%x"`, size, mod.UnixNano())
+}
+
+// TCP file 청크를 io.ReadSeeker로(크기는 stat 기준으로 고정)
+type remoteFile struct {
+	ctx    context.Context
+	tcp    *tcpclient.Client
+	reqID  string
+	userID string
+	path   string
+	size   int64
+
+	// 현재 위치
+	off int64
+	// 마지막으로 받은 청크와 그 시작 오프셋
+	buf    []byte
+	bufOff int64
+
+	// 청크 요청 수(request_id 접미사)
+	calls int
+	// 가져온 범위(로그용)
+	firstOffset int64
+	fetched     int64
+	// 실패 응답
+	failed *tcpclient.Res
+}
+
+// 파일 크기가 바뀌어 예정보다 일찍 끝남
+var errShortFile = errors.New("file changed during download")
+
+// 앞부분 n바이트(버퍼에 남아 이후 Read가 재사용)
+func (f *remoteFile) peek(n int) ([]byte, error) {
+	if f.size == 0 {
+		return nil, nil
+	}
+	if err := f.fetch(0, int64(n)); err != nil {
+		return nil, err
+	}
+	return f.buf, nil
+}
+
+func (f *remoteFile) Read(p []byte) (int, error) {
+	if f.off >= f.size {
+		return 0, io.EOF
+	}
+
+	// 버퍼 밖이면 새 청크
+	if f.off < f.bufOff || f.off >= f.bufOff+int64(len(f.buf)) {
+		if err := f.fetch(f.off, downloadChunk); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, f.buf[f.off-f.bufOff:])
+	f.off += int64(n)
+	return n, nil
+}
+
+func (f *remoteFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.off
+	case io.SeekEnd:
+		offset += f.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	f.off = offset
+	return offset, nil
+}
+
+// offset부터 최대 limit바이트 받기(stat 크기를 넘는 부분은 버림)
+func (f *remoteFile) fetch(offset, limit int64) error {
+	limit = min(limit, f.size-offset)
+	if limit <= 0 {
+		return errShortFile
+	}
+
+	f.calls++
+	res := f.tcp.Call(f.ctx, tcpclient.Req{
+		RequestID: fmt.Sprintf("%s-%d", f.reqID, f.calls),
+		UserID:    f.userID,
+		Type:      "file",
+		Path:      f.path,
+		Offset:    offset,
+		Limit:     limit,
+	})
+	if !res.Ok {
+		f.failed = &res
+		return errors.New(res.Error)
+	}
+	// 비었거나 크기가 stat과 다르면 도중에 바뀐 파일(구버전 서버는 file_size 없음)
+	if len(res.Data) == 0 || (res.FileSize > 0 && res.FileSize != f.size) {
+		res.Code, res.Error = tcpclient.CodeIOError, errShortFile.Error()
+		f.failed = &res
+		return errShortFile
+	}
+
+	if f.fetched == 0 {
+		f.firstOffset = offset
+	}
+	f.buf = res.Data[:min(int64(len(res.Data)), limit)]
+	f.bufOff = offset
+	f.fetched += int64(len(f.buf))
+	return nil
+}
+
+// 헤더 전송 전 실패 → problem+json
+func (f *remoteFile) writeProblem(w http.ResponseWriter, r *http.Request) {
+	res := *f.failed
+	res.RequestID = f.reqID
+	writeTCPProblem(w, r, res)
+}
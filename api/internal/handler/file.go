@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"encoding/base64"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"golang-network-labs/api/internal/auth"
+	"golang-network-labs/api/internal/problem"
 	"golang-network-labs/api/internal/tcpclient"
 )
 
@@ -26,6 +29,11 @@ type FileReadResult struct {
 	FileB64    string `json:"file_b64,omitempty" yaml:"file_b64,omitempty"`
 	NextOffset int64  `json:"next_offset,omitempty" yaml:"next_offset,omitempty"`
 	EOF        bool   `json:"eof,omitempty" yaml:"eof,omitempty"`
+
+	// 진행률/무결성(청크 체크섬은 TCP 응답에서 이미 검증됨)
+	FileSize int64  `json:"file_size" yaml:"file_size"`
+	CRC32C   string `json:"crc32c,omitempty" yaml:"crc32c,omitempty"`
+	SHA256   string `json:"sha256,omitempty" yaml:"sha256,omitempty"`
 }
 
 // /file: TCP로 파일 청크 읽기
@@ -35,15 +43,15 @@ func (h *Handler) File(w http.ResponseWriter, r *http.Request) {
 	// 종료 시 감소
 	defer decInFlight()
 
-	// user_id 추출
-	userID := userIDFromReq(r.Header)
+	// 인증된 사용자 ID
+	userID := auth.UserID(r.Context())
 	// request_id 생성
 	reqID := newRequestID()
 
 	// path 파라미터
 	path := strings.TrimSpace(r.URL.Query().Get("path"))
 	if path == "" {
-		http.Error(w, "path required", http.StatusBadRequest)
+		problem.Error(w, r, tcpclient.CodeBadRequest, "path required")
 		return
 	}
 
@@ -86,6 +94,12 @@ func (h *Handler) File(w http.ResponseWriter, r *http.Request) {
 		now(), reqID, userID, path, offset, limit, boolToInt(res.Ok), nullableErr(res.Error),
 	)
 
+	// TCP 실패는 problem+json
+	if !res.Ok {
+		writeTCPProblem(w, r, res)
+		return
+	}
+
 	// 응답 구성
 	out := FileReadResult{
 		RequestID:  reqID,
@@ -95,9 +109,12 @@ func (h *Handler) File(w http.ResponseWriter, r *http.Request) {
 		Limit:      limit,
 		Ok:         res.Ok,
 		Error:      res.Error,
-		FileB64:    res.FileB64,
+		FileB64:    base64.StdEncoding.EncodeToString(res.Data),
 		NextOffset: res.NextOffset,
 		EOF:        res.EOF,
+		FileSize:   res.FileSize,
+		CRC32C:     res.CRC32C,
+		SHA256:     res.SHA256,
 	}
 
 	// 응답 반환(JSON/YAML)
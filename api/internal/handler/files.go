@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang-network-labs/api/internal/auth"
+	"golang-network-labs/api/internal/problem"
+	"golang-network-labs/api/internal/tcpclient"
+)
+
+// /files 응답 스키마
+type FileListResult struct {
+	// 추적용 ID
+	RequestID string `json:"request_id,omitempty" yaml:"request_id,omitempty"`
+	// 사용자 ID
+	UserID string `json:"user_id,omitempty" yaml:"user_id,omitempty"`
+
+	// 조회 파라미터
+	Path    string `json:"path" yaml:"path"`
+	Pattern string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Offset  int64  `json:"offset,omitempty" yaml:"offset,omitempty"`
+	Limit   int64  `json:"limit,omitempty" yaml:"limit,omitempty"`
+
+	// 항목(이름순)
+	Entries []tcpclient.FileEntry `json:"entries" yaml:"entries"`
+	// 다음 페이지 offset(eof면 없음)
+	NextOffset int64 `json:"next_offset,omitempty" yaml:"next_offset,omitempty"`
+	// 마지막 페이지 여부
+	EOF bool `json:"eof" yaml:"eof"`
+}
+
+// /files/stat 응답 스키마
+type FileStatResult struct {
+	// 추적용 ID
+	RequestID string `json:"request_id,omitempty" yaml:"request_id,omitempty"`
+	// 사용자 ID
+	UserID string `json:"user_id,omitempty" yaml:"user_id,omitempty"`
+
+	// 파일 정보
+	Entry tcpclient.FileEntry `json:"entry" yaml:"entry"`
+}
+
+// /files/hash 응답 스키마
+type FileHashResult struct {
+	// 추적용 ID
+	RequestID string `json:"request_id,omitempty" yaml:"request_id,omitempty"`
+	// 사용자 ID
+	UserID string `json:"user_id,omitempty" yaml:"user_id,omitempty"`
+
+	// 파일 경로/크기
+	Path string `json:"path" yaml:"path"`
+	Size int64  `json:"size" yaml:"size"`
+	// 파일 전체 체크섬(hex)
+	SHA256 string `json:"sha256" yaml:"sha256"`
+	CRC32C string `json:"crc32c" yaml:"crc32c"`
+	// 계산한 파일 정보
+	Entry *tcpclient.FileEntry `json:"entry,omitempty" yaml:"entry,omitempty"`
+}
+
+// 파일 전체 해시 계산 제한시간(큰 파일은 TCP IO 제한시간보다 오래 걸림)
+const hashTimeout = 2 * time.Minute
+
+// /files: TCP로 디렉터리 항목 조회(path가 비면 기본 루트)
+func (h *Handler) Files(w http.ResponseWriter, r *http.Request) {
+	// inFlight 증가
+	incInFlight()
+	// 종료 시 감소
+	defer decInFlight()
+
+	q := r.URL.Query()
+	userID := auth.UserID(r.Context())
+	reqID := newRequestID()
+
+	// 페이지 파라미터(잘못된 값은 400)
+	offset, ok := queryInt(q.Get("offset"))
+	if !ok {
+		problem.Error(w, r, tcpclient.CodeBadRequest, "offset must be a non-negative integer")
+		return
+	}
+	limit, ok := queryInt(q.Get("limit"))
+	if !ok {
+		problem.Error(w, r, tcpclient.CodeBadRequest, "limit must be a non-negative integer")
+		return
+	}
+
+	tcpReq := tcpclient.Req{
+		RequestID: reqID,
+		UserID:    userID,
+		Type:      "list",
+		Path:      strings.TrimSpace(q.Get("path")),
+		Offset:    offset,
+		Limit:     limit,
+		Pattern:   strings.TrimSpace(q.Get("pattern")),
+	}
+
+	// TCP 호출
+	res := h.tcp.Call(r.Context(), tcpReq)
+	if !res.Ok {
+		writeTCPProblem(w, r, res)
+		return
+	}
+
+	out := FileListResult{
+		RequestID: reqID,
+		UserID:    userID,
+		Path:      tcpReq.Path,
+		Pattern:   tcpReq.Pattern,
+		Offset:    offset,
+		Limit:     limit,
+		Entries:   res.Entries,
+		EOF:       res.EOF,
+	}
+	if out.Entries == nil {
+		out.Entries = []tcpclient.FileEntry{}
+	}
+	if !res.EOF {
+		out.NextOffset = res.NextOffset
+	}
+
+	writeResponse(w, r, out)
+}
+
+// /files/stat: TCP로 파일 정보 조회
+func (h *Handler) FileStat(w http.ResponseWriter, r *http.Request) {
+	// inFlight 증가
+	incInFlight()
+	// 종료 시 감소
+	defer decInFlight()
+
+	userID := auth.UserID(r.Context())
+	reqID := newRequestID()
+
+	// TCP 호출(빈 path는 기본 루트)
+	res := h.tcp.Call(r.Context(), tcpclient.Req{
+		RequestID: reqID,
+		UserID:    userID,
+		Type:      "stat",
+		Path:      strings.TrimSpace(r.URL.Query().Get("path")),
+	})
+	if !res.Ok {
+		writeTCPProblem(w, r, res)
+		return
+	}
+	// 정보 없는 성공은 서버 버전 불일치
+	if res.Entry == nil {
+		res.Code = tcpclient.CodeIncompatible
+		res.Error = "tcp server returned no file entry"
+		writeTCPProblem(w, r, res)
+		return
+	}
+
+	writeResponse(w, r, FileStatResult{
+		RequestID: reqID,
+		UserID:    userID,
+		Entry:     *res.Entry,
+	})
+}
+
+// /files/hash: TCP 서버가 파일 전체의 SHA-256/CRC32C 계산(내용은 전송하지 않음)
+func (h *Handler) FileHash(w http.ResponseWriter, r *http.Request) {
+	// inFlight 증가
+	incInFlight()
+	// 종료 시 감소
+	defer decInFlight()
+
+	userID := auth.UserID(r.Context())
+	reqID := newRequestID()
+
+	p := strings.TrimSpace(r.URL.Query().Get("path"))
+	if p == "" {
+		problem.Error(w, r, tcpclient.CodeBadRequest, "path required")
+		return
+	}
+
+	// TCP 호출(클라이언트가 끊으면 서버 계산도 취소)
+	res := h.tcp.Call(r.Context(), tcpclient.Req{
+		RequestID: reqID,
+		UserID:    userID,
+		Type:      "hash",
+		Path:      p,
+		TimeoutMs: hashTimeout.Milliseconds(),
+	})
+	if !res.Ok {
+		writeTCPProblem(w, r, res)
+		return
+	}
+	// 해시 없는 성공은 서버 버전 불일치
+	if res.SHA256 == "" {
+		res.Code = tcpclient.CodeIncompatible
+		res.Error = "tcp server returned no checksum"
+		writeTCPProblem(w, r, res)
+		return
+	}
+
+	writeResponse(w, r, FileHashResult{
+		RequestID: reqID,
+		UserID:    userID,
+		Path:      p,
+		Size:      res.FileSize,
+		SHA256:    res.SHA256,
+		CRC32C:    res.CRC32C,
+		Entry:     res.Entry,
+	})
+}
+
+// 0 이상 정수 쿼리(비면 0)
+func queryInt(v string) (int64, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, true
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"golang-network-labs/api/internal/jobs"
 	"golang-network-labs/api/internal/tcpclient"
 )
 
@@ -14,16 +15,19 @@ import (
 type Deps struct {
 	DB  *sql.DB
 	TCP *tcpclient.Client
+	// 비동기 작업
+	Jobs *jobs.Manager
 }
 
 // 핸들러 본체
 type Handler struct {
-	db  *sql.DB
-	tcp *tcpclient.Client
+	db   *sql.DB
+	tcp  *tcpclient.Client
+	jobs *jobs.Manager
 }
 
 func New(d Deps) *Handler {
-	return &Handler{db: d.DB, tcp: d.TCP}
+	return &Handler{db: d.DB, tcp: d.TCP, jobs: d.Jobs}
 }
 
 func boolToInt(b bool) int {
@@ -33,17 +37,6 @@ func boolToInt(b bool) int {
 	return 0
 }
 
-// user_id 헤더 추출
-func userIDFromReq(rh interface{ Get(string) string }) string {
-	// 헤더 값 읽기
-	u := strings.TrimSpace(rh.Get("X-User-Id"))
-	// 없으면 기본값
-	if u == "" {
-		return "anonymous"
-	}
-	return u
-}
-
 // request_id 생성
 func newRequestID() string {
 	// 8바이트 랜덤
@@ -62,6 +55,14 @@ func nullableErr(s string) any {
 	return s
 }
 
+// 실행되지 않은 명령의 종료 코드는 NULL 처리
+func nullableExitCode(code *int, startedAt time.Time) any {
+	if code == nil || startedAt.IsZero() {
+		return nil
+	}
+	return *code
+}
+
 // 로그 저장용 공통 시간
 func now() time.Time {
 	return time.Now()
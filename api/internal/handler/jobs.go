@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"golang-network-labs/api/internal/auth"
+	"golang-network-labs/api/internal/jobs"
+	"golang-network-labs/api/internal/problem"
+	"golang-network-labs/api/internal/tcpclient"
+)
+
+// POST /jobs: 명령을 작업으로 등록하고 바로 반환
+func (h *Handler) CreateJob(w http.ResponseWriter, r *http.Request) {
+	// 인증된 사용자 ID
+	userID := auth.UserID(r.Context())
+
+	// cmd 파싱(/run과 같은 입력 형식)
+	cmd, timeoutMs, ok := readCmdInput(w, r)
+	if !ok {
+		return
+	}
+
+	// 작업 등록
+	j, err := h.jobs.Submit(r.Context(), userID, cmd, timeoutMs)
+	if errors.Is(err, jobs.ErrQueueFull) {
+		problem.Error(w, r, tcpclient.CodeQueueFull, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("jobs: submit: %v", err)
+		problem.Error(w, r, tcpclient.CodeInternal, "could not create job")
+		return
+	}
+
+	// 202 + 상태 URL
+	w.Header().Set("Location", "/jobs/"+j.ID)
+	writeStatus(w, r, http.StatusAccepted, j)
+}
+
+// GET /jobs/{id}: 상태/결과 조회
+func (h *Handler) GetJob(w http.ResponseWriter, r *http.Request) {
+	j, err := h.jobs.Get(r.Context(), auth.UserID(r.Context()), chi.URLParam(r, "id"))
+	if err != nil {
+		writeJobError(w, r, err)
+		return
+	}
+	writeResponse(w, r, j)
+}
+
+// DELETE /jobs/{id}: 대기/실행중 작업 취소
+func (h *Handler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	j, err := h.jobs.Cancel(r.Context(), auth.UserID(r.Context()), chi.URLParam(r, "id"))
+	if err != nil {
+		writeJobError(w, r, err)
+		return
+	}
+	writeResponse(w, r, j)
+}
+
+// 작업 에러 → problem+json
+func writeJobError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		problem.Error(w, r, tcpclient.CodeNotFound, err.Error())
+	case errors.Is(err, jobs.ErrFinished):
+		problem.Error(w, r, problem.CodeJobFinished, err.Error())
+	default:
+		log.Printf("jobs: %v", err)
+		problem.Error(w, r, tcpclient.CodeInternal, "job lookup failed")
+	}
+}
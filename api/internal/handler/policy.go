@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+
+	"golang-network-labs/api/internal/auth"
+	"golang-network-labs/api/internal/tcpclient"
+)
+
+// TCP 서버에 적용중인 명령 정책 조회(관리용)
+// - 인증된 호출자 ID로 요청(조회 권한은 TCP 서버 authz가 판단)
+func (h *Handler) Policy(w http.ResponseWriter, r *http.Request) {
+	res := h.tcp.Call(r.Context(), tcpclient.Req{
+		RequestID: newRequestID(),
+		UserID:    auth.UserID(r.Context()),
+		Type:      "policy",
+	})
+
+	// 실패 → problem+json
+	if !res.Ok {
+		writeTCPProblem(w, r, res)
+		return
+	}
+	// 정책 없는 성공은 서버 버전 불일치
+	if res.Policy == nil {
+		res.Code = tcpclient.CodeIncompatible
+		res.Error = "tcp server returned no policy"
+		writeTCPProblem(w, r, res)
+		return
+	}
+
+	// 버전 해시는 헤더로도 제공
+	w.Header().Set("ETag", `"`+res.Policy.Version+`"`)
+	writeResponse(w, r, res.Policy)
+}
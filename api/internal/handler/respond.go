@@ -5,6 +5,9 @@ import (
 	"net/http"
 	"strings"
 
+	"golang-network-labs/api/internal/problem"
+	"golang-network-labs/api/internal/tcpclient"
+
 	"gopkg.in/yaml.v3"
 )
 
@@ -21,9 +24,15 @@ func wantYAML(r *http.Request) bool {
 
 // 공통 응답 작성(JSON/YAML)
 func writeResponse(w http.ResponseWriter, r *http.Request, v any) {
+	writeStatus(w, r, http.StatusOK, v)
+}
+
+// 상태 코드 지정 응답 작성(JSON/YAML)
+func writeStatus(w http.ResponseWriter, r *http.Request, status int, v any) {
 	// YAML이면 YAML로 반환
 	if wantYAML(r) {
 		w.Header().Set("Content-Type", "application/x-yaml; charset=utf-8")
+		w.WriteHeader(status)
 		b, _ := yaml.Marshal(v)
 		_, _ = w.Write(b)
 		return
@@ -31,5 +40,21 @@ func writeResponse(w http.ResponseWriter, r *http.Request, v any) {
 
 	// 기본은 JSON
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
 	_ = json.NewEncoder(w).Encode(v)
 }
+
+// TCP 실패 → problem+json(실행된 명령이면 결과 포함)
+func writeTCPProblem(w http.ResponseWriter, r *http.Request, res tcpclient.Res) {
+	// 코드 없는 실패는 내부 오류
+	code := res.Code
+	if code == "" {
+		code = tcpclient.CodeInternal
+	}
+	p := problem.New(code, res.Error)
+	p.RequestID = res.RequestID
+	if !res.StartedAt.IsZero() {
+		p.Result = res
+	}
+	problem.Write(w, r, p)
+}
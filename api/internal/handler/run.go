@@ -2,9 +2,13 @@ package handler
 
 import (
 	"encoding/json"
+	"log"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"golang-network-labs/api/internal/auth"
+	"golang-network-labs/api/internal/problem"
 	"golang-network-labs/api/internal/tcpclient"
 
 	"gopkg.in/yaml.v3"
@@ -16,18 +20,69 @@ func (h *Handler) Run(w http.ResponseWriter, r *http.Request) {
 	// 종료 시 감소
 	defer decInFlight()
 
-	// user_id 추출
-	userID := userIDFromReq(r.Header)
+	// 인증된 사용자 ID
+	userID := auth.UserID(r.Context())
 	// request_id 생성
 	reqID := newRequestID()
 
-	var cmd string
-
 	// 1) cmd 파싱 (GET + POST)
+	cmd, timeoutMs, ok := readCmdInput(w, r)
+	if !ok {
+		return
+	}
+
+	// TCP 요청 구성
+	tcpReq := tcpclient.Req{
+		RequestID: reqID,
+		UserID:    userID,
+		Type:      "cmd",
+		Cmd:       cmd,
+		TimeoutMs: timeoutMs,
+	}
+
+	// 스트리밍 요청은 SSE로 중계
+	if wantStream(r) {
+		h.runStream(w, r, tcpReq)
+		return
+	}
+
+	// TCP 호출(컨텍스트 포함)
+	res := h.tcp.Call(r.Context(), tcpReq)
+
+	// 실행 로그 저장
+	h.recordRun(tcpReq, res)
+
+	// TCP 실패는 problem+json(0이 아닌 종료 코드는 실행 결과이므로 200)
+	if !res.Ok && res.Code != tcpclient.CodeExitNonZero {
+		writeTCPProblem(w, r, res)
+		return
+	}
+
+	// 응답 반환(JSON/YAML)
+	writeResponse(w, r, res)
+}
+
+// logs 저장(실패는 로그로 남김)
+func (h *Handler) recordRun(req tcpclient.Req, res tcpclient.Res) {
+	_, err := h.db.Exec(
+		`INSERT INTO logs(ts, request_id, user_id, cmd, ok, tcp_local, tcp_remote, err_msg, code, exit_code, duration_ms)
+		 VALUES (?,?,?,?,?,?,?,?,?,?,?)`,
+		now(), req.RequestID, req.UserID, req.Cmd, boolToInt(res.Ok), res.TcpLocal, res.TcpRemote, nullableErr(res.Error), nullableErr(res.Code),
+		nullableExitCode(res.ExitCode, res.StartedAt), res.DurationMs,
+	)
+	if err != nil {
+		log.Printf("logs insert request_id=%s: %v", req.RequestID, err)
+	}
+}
+
+// cmd + timeout_ms 읽기(GET 쿼리, POST JSON/YAML/form/multipart)
+// - 실패면 problem 응답을 쓰고 false
+func readCmdInput(w http.ResponseWriter, r *http.Request) (cmd string, timeoutMs int64, ok bool) {
 	switch r.Method {
 	case http.MethodGet:
 		// GET 쿼리에서 cmd
 		cmd = r.URL.Query().Get("cmd")
+		timeoutMs = parseTimeoutMs(r.URL.Query().Get("timeout_ms"))
 
 	case http.MethodPost:
 		// body 크기 제한(1MB)
@@ -39,70 +94,63 @@ func (h *Handler) Run(w http.ResponseWriter, r *http.Request) {
 		if strings.HasPrefix(ct, "application/json") {
 			var req tcpclient.Req
 			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-				http.Error(w, "invalid json", http.StatusBadRequest)
-				return
+				problem.Error(w, r, tcpclient.CodeBadRequest, "invalid json")
+				return "", 0, false
 			}
 			cmd = req.Cmd
+			timeoutMs = req.TimeoutMs
 		} else if strings.HasPrefix(ct, "application/x-yaml") || strings.HasPrefix(ct, "text/yaml") {
 			// YAML 요청 처리
 			var req tcpclient.Req
 			if err := yaml.NewDecoder(r.Body).Decode(&req); err != nil {
-				http.Error(w, "invalid yaml", http.StatusBadRequest)
-				return
+				problem.Error(w, r, tcpclient.CodeBadRequest, "invalid yaml")
+				return "", 0, false
 			}
 			cmd = req.Cmd
+			timeoutMs = req.TimeoutMs
 		} else if strings.HasPrefix(ct, "application/x-www-form-urlencoded") {
 			// form 파싱
 			if err := r.ParseForm(); err != nil {
-				http.Error(w, "invalid form", http.StatusBadRequest)
-				return
+				problem.Error(w, r, tcpclient.CodeBadRequest, "invalid form")
+				return "", 0, false
 			}
 			cmd = r.Form.Get("cmd")
+			timeoutMs = parseTimeoutMs(r.Form.Get("timeout_ms"))
 		} else if strings.HasPrefix(ct, "multipart/form-data") {
 			// multipart 파싱
 			if err := r.ParseMultipartForm(1 << 20); err != nil {
-				http.Error(w, "invalid multipart form", http.StatusBadRequest)
-				return
+				problem.Error(w, r, tcpclient.CodeBadRequest, "invalid multipart form")
+				return "", 0, false
 			}
 			cmd = r.FormValue("cmd")
+			timeoutMs = parseTimeoutMs(r.FormValue("timeout_ms"))
 		} else {
 			// 지원하지 않는 타입
-			http.Error(w, "unsupported content-type", http.StatusUnsupportedMediaType)
-			return
+			problem.Error(w, r, problem.CodeUnsupportedMediaType, "unsupported content-type")
+			return "", 0, false
 		}
 
 	default:
 		// 지원하지 않는 메서드
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
+		problem.Error(w, r, problem.CodeMethodNotAllowed, "method not allowed")
+		return "", 0, false
 	}
 
 	// cmd 공백 제거
 	cmd = strings.TrimSpace(cmd)
 	// 빈 cmd 거절
 	if cmd == "" {
-		http.Error(w, "cmd required", http.StatusBadRequest)
-		return
+		problem.Error(w, r, tcpclient.CodeBadRequest, "cmd required")
+		return "", 0, false
 	}
+	return cmd, timeoutMs, true
+}
 
-	// TCP 요청 구성
-	tcpReq := tcpclient.Req{
-		RequestID: reqID,
-		UserID:    userID,
-		Type:      "cmd",
-		Cmd:       cmd,
+// timeout_ms 파싱(잘못된 값은 서버 기본값)
+func parseTimeoutMs(v string) int64 {
+	n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+	if err != nil || n <= 0 {
+		return 0
 	}
-
-	// TCP 호출(컨텍스트 포함)
-	res := h.tcp.Call(r.Context(), tcpReq)
-
-	// 실행 로그 저장
-	_, _ = h.db.Exec(
-		`INSERT INTO logs(ts, request_id, user_id, cmd, ok, tcp_local, tcp_remote, err_msg)
-		 VALUES (?,?,?,?,?,?,?,?)`,
-		now(), reqID, userID, cmd, boolToInt(res.Ok), res.TcpLocal, res.TcpRemote, nullableErr(res.Error),
-	)
-
-	// 응답 반환(JSON/YAML)
-	writeResponse(w, r, res)
+	return n
 }
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang-network-labs/api/internal/tcpclient"
+)
+
+// stream=1 요청 여부
+func wantStream(r *http.Request) bool {
+	v := strings.TrimSpace(r.URL.Query().Get("stream"))
+	return v == "1" || strings.EqualFold(v, "true")
+}
+
+// /run?stream=1: TCP 이벤트를 SSE로 중계
+func (h *Handler) runStream(w http.ResponseWriter, r *http.Request, tcpReq tcpclient.Req) {
+	// flush 지원 확인
+	rc := http.NewResponseController(w)
+
+	// SSE 헤더
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// 프록시 버퍼링 방지
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	// 최종 결과(로그 저장용)
+	res := tcpclient.Res{Ok: false, Error: "stream ended without exit", RequestID: tcpReq.RequestID, UserID: tcpReq.UserID}
+
+	for ev := range h.tcp.Stream(r.Context(), tcpReq) {
+		// exit 이벤트는 최종 결과 보관
+		if ev.Event == tcpclient.EventExit && ev.Result != nil {
+			res = *ev.Result
+		}
+
+		// 브라우저로 전달(실패해도 exit까지 소비)
+		_ = writeSSE(w, ev)
+		_ = rc.Flush()
+	}
+
+	// 실행 로그 저장
+	h.recordRun(tcpReq, res)
+}
+
+// SSE 이벤트 한 건 작성
+func writeSSE(w http.ResponseWriter, ev tcpclient.Event) error {
+	// 종류별 payload
+	var payload any = map[string]string{"data": ev.Data}
+	if ev.Event == tcpclient.EventExit {
+		payload = ev.Result
+	}
+
+	// data는 JSON 한 줄
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Event, b)
+	return err
+}
@@ -7,6 +7,8 @@ import (
 	"net/url"
 	"strings"
 
+	"golang-network-labs/api/internal/auth"
+	"golang-network-labs/api/internal/problem"
 	"golang-network-labs/api/internal/tcpclient"
 
 	"golang.org/x/net/html"
@@ -33,8 +35,8 @@ func (h *Handler) Title(w http.ResponseWriter, r *http.Request) {
 	// 종료 시 감소
 	defer decInFlight()
 
-	// user_id 추출
-	userID := userIDFromReq(r.Header)
+	// 인증된 사용자 ID
+	userID := auth.UserID(r.Context())
 	// request_id 생성
 	reqID := newRequestID()
 
@@ -42,7 +44,7 @@ func (h *Handler) Title(w http.ResponseWriter, r *http.Request) {
 	raw := strings.TrimSpace(r.URL.Query().Get("url"))
 	// 없으면 거절
 	if raw == "" {
-		http.Error(w, "url required", http.StatusBadRequest)
+		problem.Error(w, r, tcpclient.CodeBadRequest, "url required")
 		return
 	}
 
@@ -50,20 +52,20 @@ func (h *Handler) Title(w http.ResponseWriter, r *http.Request) {
 	title, links, err := fetchTitleAndLinks(raw)
 	// 실패면 에러
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		problem.Error(w, r, problem.CodeFetchFailed, err.Error())
 		return
 	}
 
 	// url_results 저장
 	resultID, err := insertURLResult(h.db, reqID, userID, raw, title)
 	if err != nil {
-		http.Error(w, "db insert url_results failed: "+err.Error(), http.StatusInternalServerError)
+		problem.Error(w, r, tcpclient.CodeInternal, "db insert url_results failed: "+err.Error())
 		return
 	}
 
 	if len(links) > 0 {
 		if err := insertURLLinks(h.db, resultID, links); err != nil {
-			http.Error(w, "db insert url_links failed: "+err.Error(), http.StatusInternalServerError)
+			problem.Error(w, r, tcpclient.CodeInternal, "db insert url_links failed: "+err.Error())
 			return
 		}
 	}
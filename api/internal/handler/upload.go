@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang-network-labs/api/internal/auth"
+	"golang-network-labs/api/internal/problem"
+	"golang-network-labs/api/internal/tcpclient"
+)
+
+// 업로드 청크 크기(TCP 프레임 한도 안)
+const uploadChunk = 1 << 20
+
+// 청크 하나를 받는 동안의 body 읽기 제한시간(서버 ReadTimeout 대신)
+const uploadReadTimeout = 30 * time.Second
+
+// 클라이언트가 보내는 본문 SHA-256(hex)
+const sha256Header = "X-Content-SHA256"
+
+// PUT /files 응답 스키마
+type FileWriteResult struct {
+	// 추적용 ID
+	RequestID string `json:"request_id,omitempty" yaml:"request_id,omitempty"`
+	// 사용자 ID
+	UserID string `json:"user_id,omitempty" yaml:"user_id,omitempty"`
+
+	// 저장 경로
+	Path string `json:"path" yaml:"path"`
+	// 받은 크기/해시
+	Size   int64  `json:"size" yaml:"size"`
+	SHA256 string `json:"sha256" yaml:"sha256"`
+	// 새 파일 여부(false면 덮어씀)
+	Created bool `json:"created" yaml:"created"`
+	// 저장된 파일 정보
+	Entry *tcpclient.FileEntry `json:"entry,omitempty" yaml:"entry,omitempty"`
+}
+
+// PUT /files: 요청 본문을 청크로 TCP에 보내고 SHA-256 확인 후 원자적으로 저장
+func (h *Handler) PutFile(w http.ResponseWriter, r *http.Request) {
+	// inFlight 증가
+	incInFlight()
+	// 종료 시 감소
+	defer decInFlight()
+
+	userID := auth.UserID(r.Context())
+	reqID := newRequestID()
+
+	p := strings.TrimSpace(r.URL.Query().Get("path"))
+	if p == "" {
+		problem.Error(w, r, tcpclient.CodeBadRequest, "path required")
+		return
+	}
+
+	// 클라이언트 체크섬(없으면 API가 계산한 값으로 전송 구간만 검증)
+	want := strings.ToLower(strings.TrimSpace(r.Header.Get(sha256Header)))
+	if want != "" {
+		if b, err := hex.DecodeString(want); err != nil || len(b) != sha256.Size {
+			problem.Error(w, r, tcpclient.CodeBadRequest, sha256Header+" must be 64 hex characters")
+			return
+		}
+	}
+
+	// 업로드 열기(본문을 읽기 전이라 거절되면 100-continue 클라이언트는 전송 안 함)
+	res := h.tcp.Call(r.Context(), tcpclient.Req{
+		RequestID: reqID,
+		UserID:    userID,
+		Type:      "write",
+		Op:        "open",
+		Path:      p,
+	})
+	if !res.Ok {
+		h.recordWrite(reqID, userID, p, 0, want, res)
+		writeTCPProblem(w, r, res)
+		return
+	}
+	if res.UploadID == "" {
+		res.Ok, res.Code, res.Error = false, tcpclient.CodeIncompatible, "tcp server returned no upload id"
+		writeTCPProblem(w, r, res)
+		return
+	}
+	up := upload{h: h, reqID: reqID, userID: userID, id: res.UploadID}
+
+	// 청크 전송
+	rc := http.NewResponseController(w)
+	sum := sha256.New()
+	buf := make([]byte, uploadChunk)
+	var size int64
+	for {
+		_ = rc.SetReadDeadline(time.Now().Add(uploadReadTimeout))
+		n, err := io.ReadFull(r.Body, buf)
+		if n > 0 {
+			sum.Write(buf[:n])
+			res = up.call(r.Context(), tcpclient.Req{Op: "append", Offset: size, Data: buf[:n]})
+			if !res.Ok {
+				up.abort(r.Context())
+				h.recordWrite(reqID, userID, p, size, want, res)
+				writeTCPProblem(w, r, res)
+				return
+			}
+			size = res.NextOffset
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			// 본문을 끝까지 못 받음(클라이언트 끊김/제한시간)
+			up.abort(r.Context())
+			res = tcpclient.Res{RequestID: reqID, Code: tcpclient.CodeBadRequest, Error: "reading request body: " + err.Error()}
+			h.recordWrite(reqID, userID, p, size, want, res)
+			writeTCPProblem(w, r, res)
+			return
+		}
+	}
+
+	// commit(TCP 서버가 받은 바이트의 해시와 비교)
+	got := hex.EncodeToString(sum.Sum(nil))
+	if want == "" {
+		want = got
+	}
+	res = up.call(r.Context(), tcpclient.Req{Op: "commit", SHA256: want})
+	h.recordWrite(reqID, userID, p, size, want, res)
+	if !res.Ok {
+		// 체크섬 불일치는 서버가 이미 정리
+		if res.Code != tcpclient.CodeChecksumMismatch {
+			up.abort(r.Context())
+		}
+		writeTCPProblem(w, r, res)
+		return
+	}
+
+	status := http.StatusOK
+	if res.Created {
+		status = http.StatusCreated
+	}
+	writeStatus(w, r, status, FileWriteResult{
+		RequestID: reqID,
+		UserID:    userID,
+		Path:      p,
+		Size:      size,
+		SHA256:    want,
+		Created:   res.Created,
+		Entry:     res.Entry,
+	})
+}
+
+// 진행중인 업로드(청크마다 request_id 접미사)
+type upload struct {
+	h      *Handler
+	reqID  string
+	userID string
+	id     string
+	calls  int
+}
+
+// write 요청 한 건
+func (u *upload) call(ctx context.Context, req tcpclient.Req) tcpclient.Res {
+	u.calls++
+	req.RequestID = fmt.Sprintf("%s-%d", u.reqID, u.calls)
+	req.UserID = u.userID
+	req.Type = "write"
+	req.UploadID = u.id
+	res := u.h.tcp.Call(ctx, req)
+	// 문제 응답은 원래 request_id로
+	res.RequestID = u.reqID
+	return res
+}
+
+// 임시 파일 정리(클라이언트가 끊겨도 수행, 실패는 서버 만료에 맡김)
+func (u *upload) abort(ctx context.Context) {
+	res := u.call(context.WithoutCancel(ctx), tcpclient.Req{Op: "abort"})
+	if !res.Ok {
+		log.Printf("upload abort request_id=%s upload_id=%s: %s", u.reqID, u.id, res.Error)
+	}
+}
+
+// file_writes 로그 저장
+func (h *Handler) recordWrite(reqID, userID, path string, size int64, sha string, res tcpclient.Res) {
+	_, _ = h.db.Exec(
+		`INSERT INTO file_writes(ts, request_id, user_id, file_path, size_bytes, sha256, ok, err_msg, code)
+		 VALUES (?,?,?,?,?,?,?,?,?)`,
+		now(), reqID, userID, path, size, nullableErr(sha), boolToInt(res.Ok), nullableErr(res.Error), nullableErr(res.Code),
+	)
+}
@@ -0,0 +1,629 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang-network-labs/api/internal/tcpclient"
+)
+
+// 작업 상태
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// API 재시작으로 결과를 잃은 작업의 에러 코드
+const CodeInterrupted = "INTERRUPTED"
+
+// 작업 조회/취소 실패
+var (
+	ErrNotFound = errors.New("job not found")
+	ErrFinished = errors.New("job already finished")
+	ErrClosed   = errors.New("job manager closed")
+	// 실행 대기 작업이 상한을 넘음
+	ErrQueueFull = errors.New("too many pending jobs")
+)
+
+// 작업 기본값
+const (
+	defaultMaxConcurrency = 4
+	defaultMaxQueued      = 100
+	defaultTimeout        = 60 * time.Second
+	defaultLeaseTTL       = 30 * time.Second
+)
+
+// 작업 실행 설정
+type Config struct {
+	// 동시에 실행할 작업 수
+	MaxConcurrency int
+	// 실행 슬롯을 기다릴 수 있는 작업 수(넘치면 ErrQueueFull)
+	MaxQueued int
+	// timeout_ms가 없을 때 실행 제한시간
+	DefaultTimeout time.Duration
+	// 작업 소유 기간(인스턴스가 죽으면 이 시간 뒤 다른 인스턴스가 정리)
+	LeaseTTL time.Duration
+}
+
+// 작업 한 건
+type Job struct {
+	ID        string `json:"id" yaml:"id"`
+	UserID    string `json:"user_id" yaml:"user_id"`
+	Cmd       string `json:"cmd" yaml:"cmd"`
+	TimeoutMs int64  `json:"timeout_ms,omitempty" yaml:"timeout_ms,omitempty"`
+	Status    string `json:"status" yaml:"status"`
+
+	CreatedAt  time.Time  `json:"created_at" yaml:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty" yaml:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" yaml:"finished_at,omitempty"`
+
+	// 종료된 작업의 실행 결과
+	Result *tcpclient.Res `json:"result,omitempty" yaml:"result,omitempty"`
+}
+
+// 종료 상태 여부
+func (j Job) Finished() bool {
+	switch j.Status {
+	case StatusSucceeded, StatusFailed, StatusCancelled:
+		return true
+	}
+	return false
+}
+
+// 작업 관리자(DB에 상태 저장 + TCP 실행)
+type Manager struct {
+	db  *sql.DB
+	tcp *tcpclient.Client
+	cfg Config
+
+	// 동시 실행 슬롯
+	sem chan struct{}
+	// 이 인스턴스 ID(jobs.claimed_by)
+	owner string
+
+	// 실행중 작업 취소 함수(대기중 포함)
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+	// DB 저장 중인 Submit 수(대기 상한 계산용)
+	reserved int
+	closed   bool
+
+	// 종료 시작(대기중 작업은 실행하지 않음)
+	quit chan struct{}
+	// 종료 기한 초과 시 실행중 작업 중단
+	ctx  context.Context
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+	// 소유 갱신 루프
+	leases sync.WaitGroup
+}
+
+// 관리자 생성
+func New(db *sql.DB, tcp *tcpclient.Client, cfg Config) *Manager {
+	if cfg.MaxConcurrency <= 0 {
+		cfg.MaxConcurrency = defaultMaxConcurrency
+	}
+	if cfg.MaxQueued <= 0 {
+		cfg.MaxQueued = defaultMaxQueued
+	}
+	if cfg.DefaultTimeout <= 0 {
+		cfg.DefaultTimeout = defaultTimeout
+	}
+	if cfg.LeaseTTL <= 0 {
+		cfg.LeaseTTL = defaultLeaseTTL
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	return &Manager{
+		db:      db,
+		tcp:     tcp,
+		cfg:     cfg,
+		sem:     make(chan struct{}, cfg.MaxConcurrency),
+		owner:   newOwner(),
+		cancels: make(map[string]context.CancelFunc),
+		quit:    make(chan struct{}),
+		ctx:     ctx,
+		stop:    stop,
+	}
+}
+
+// 이전 프로세스가 남긴 작업 정리 + 소유 갱신 루프 시작
+// - 여러 API 인스턴스가 같은 DB를 써도 소유 기간이 끝난 작업만 정리
+func (m *Manager) Recover(ctx context.Context) error {
+	if err := m.sweep(ctx); err != nil {
+		return err
+	}
+	m.leases.Add(1)
+	go m.keepLeases()
+	return nil
+}
+
+// 소유 기간이 끝난 작업 정리
+// - running: 결과를 알 수 없으므로 failed(INTERRUPTED)
+// - queued: 대기 상한 안에서 이 인스턴스가 가져와 실행
+func (m *Manager) sweep(ctx context.Context) error {
+	res, err := m.db.ExecContext(ctx,
+		`UPDATE jobs SET status=?, finished_at=?, ok=0, code=?, err_msg=?, lease_until=NULL
+		 WHERE status=? AND (lease_until IS NULL OR lease_until < NOW(3))`,
+		StatusFailed, time.Now(), CodeInterrupted, "api instance stopped while the job was running", StatusRunning,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		log.Printf("jobs: marked %d orphaned running job(s) interrupted", n)
+	}
+
+	// 남은 대기 자리만큼 주인 없는 queued 작업 가져오기(오래된 순)
+	free := m.free()
+	if free == 0 {
+		return nil
+	}
+	res, err = m.db.ExecContext(ctx,
+		`UPDATE jobs SET claimed_by=?, lease_until=DATE_ADD(NOW(3), INTERVAL ? MICROSECOND)
+		 WHERE status=? AND (lease_until IS NULL OR lease_until < NOW(3))
+		 ORDER BY created_at LIMIT ?`,
+		m.owner, m.cfg.LeaseTTL.Microseconds(), StatusQueued, free,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil
+	}
+
+	rows, err := m.db.QueryContext(ctx,
+		`SELECT id, user_id, cmd, timeout_ms FROM jobs WHERE status=? AND claimed_by=? ORDER BY created_at`,
+		StatusQueued, m.owner)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var queued []Job
+	for rows.Next() {
+		var j Job
+		if err := rows.Scan(&j.ID, &j.UserID, &j.Cmd, &j.TimeoutMs); err != nil {
+			return err
+		}
+		queued = append(queued, j)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// 이미 이 인스턴스가 돌리는 작업은 건너뜀
+	n := 0
+	for _, j := range queued {
+		started, err := m.start(j)
+		if errors.Is(err, ErrClosed) {
+			// 종료 중: 가져온 작업은 Close가 소유 해제
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if started {
+			n++
+		}
+	}
+	if n > 0 {
+		log.Printf("jobs: requeued %d queued job(s)", n)
+	}
+	return nil
+}
+
+// 소유 기간 갱신 + 다른 인스턴스가 남긴 작업 정리(종료까지)
+func (m *Manager) keepLeases() {
+	defer m.leases.Done()
+
+	t := time.NewTicker(m.cfg.LeaseTTL / 3)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		ctx, cancel := context.WithTimeout(m.ctx, m.cfg.LeaseTTL/3)
+		_, err := m.db.ExecContext(ctx,
+			`UPDATE jobs SET lease_until=DATE_ADD(NOW(3), INTERVAL ? MICROSECOND)
+			 WHERE claimed_by=? AND status IN (?,?)`,
+			m.cfg.LeaseTTL.Microseconds(), m.owner, StatusQueued, StatusRunning,
+		)
+		if err != nil {
+			log.Printf("jobs: renew leases: %v", err)
+		}
+		if err := m.stopCancelled(ctx); err != nil && m.ctx.Err() == nil {
+			log.Printf("jobs: check cancelled: %v", err)
+		}
+		if err := m.sweep(ctx); err != nil && m.ctx.Err() == nil {
+			log.Printf("jobs: sweep: %v", err)
+		}
+		cancel()
+	}
+}
+
+// 다른 인스턴스에서 취소된(DELETE /jobs/{id}) 작업 중 여기서 실행중인 것 중단
+func (m *Manager) stopCancelled(ctx context.Context) error {
+	m.mu.Lock()
+	ids := make([]any, 0, len(m.cancels))
+	for id := range m.cancels {
+		ids = append(ids, id)
+	}
+	m.mu.Unlock()
+	if len(ids) == 0 {
+		return nil
+	}
+
+	args := append([]any{m.owner, StatusCancelled}, ids...)
+	rows, err := m.db.QueryContext(ctx,
+		`SELECT id FROM jobs WHERE claimed_by=? AND status=? AND id IN (?`+strings.Repeat(",?", len(ids)-1)+`)`,
+		args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		m.mu.Lock()
+		cancel := m.cancels[id]
+		m.mu.Unlock()
+		if cancel != nil {
+			log.Printf("jobs: stopping job %s cancelled on another instance", id)
+			cancel()
+		}
+	}
+	return rows.Err()
+}
+
+// 작업 등록 후 바로 반환(실행은 백그라운드)
+func (m *Manager) Submit(ctx context.Context, userID, cmd string, timeoutMs int64) (Job, error) {
+	if timeoutMs <= 0 {
+		timeoutMs = m.cfg.DefaultTimeout.Milliseconds()
+	}
+
+	// 대기 자리 확보(넘치면 DB에 남기지 않고 거절)
+	if err := m.reserve(); err != nil {
+		return Job{}, err
+	}
+	defer m.unreserve()
+
+	j := Job{
+		ID:        newID(),
+		UserID:    userID,
+		Cmd:       cmd,
+		TimeoutMs: timeoutMs,
+		Status:    StatusQueued,
+		CreatedAt: time.Now().Truncate(time.Millisecond),
+	}
+
+	// 상태 먼저 저장(재시작 후에도 조회 가능, 이 인스턴스 소유)
+	_, err := m.db.ExecContext(ctx,
+		`INSERT INTO jobs(id, user_id, cmd, timeout_ms, status, created_at, claimed_by, lease_until)
+		 VALUES (?,?,?,?,?,?,?,DATE_ADD(NOW(3), INTERVAL ? MICROSECOND))`,
+		j.ID, j.UserID, j.Cmd, j.TimeoutMs, j.Status, j.CreatedAt, m.owner, m.cfg.LeaseTTL.Microseconds(),
+	)
+	if err != nil {
+		return Job{}, err
+	}
+
+	if _, err := m.start(j); err != nil {
+		return Job{}, err
+	}
+	return j, nil
+}
+
+// 대기 자리 하나 확보
+func (m *Manager) reserve() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return ErrClosed
+	}
+	if m.pendingLocked() >= m.cfg.MaxConcurrency+m.cfg.MaxQueued {
+		return ErrQueueFull
+	}
+	m.reserved++
+	return nil
+}
+
+// 확보한 자리 반납(start 후에는 cancels로 집계됨)
+func (m *Manager) unreserve() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reserved--
+}
+
+// 더 받을 수 있는 작업 수(종료 중이면 0)
+func (m *Manager) free() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return 0
+	}
+	return max(0, m.cfg.MaxConcurrency+m.cfg.MaxQueued-m.pendingLocked())
+}
+
+// 실행중 + 대기중 + 저장중 작업 수(m.mu 보유 상태)
+func (m *Manager) pendingLocked() int {
+	return len(m.cancels) + m.reserved
+}
+
+// 실행 고루틴 시작(이미 실행중이면 false)
+func (m *Manager) start(j Job) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return false, ErrClosed
+	}
+	if _, ok := m.cancels[j.ID]; ok {
+		return false, nil
+	}
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.cancels[j.ID] = cancel
+	m.wg.Add(1)
+	go m.run(ctx, j)
+	return true, nil
+}
+
+// 작업 한 건 실행
+func (m *Manager) run(ctx context.Context, j Job) {
+	defer m.wg.Done()
+	defer m.forget(j.ID)
+
+	// 슬롯 대기(취소/종료 시 queued 그대로 둠)
+	select {
+	case m.sem <- struct{}{}:
+		defer func() { <-m.sem }()
+	case <-ctx.Done():
+		return
+	case <-m.quit:
+		return
+	}
+
+	// queued → running(그 사이 취소됐거나 다른 인스턴스가 가져갔으면 실행 안 함)
+	started := time.Now()
+	res, err := m.db.Exec(
+		`UPDATE jobs SET status=?, started_at=?, lease_until=DATE_ADD(NOW(3), INTERVAL ? MICROSECOND)
+		 WHERE id=? AND status=? AND claimed_by=?`,
+		StatusRunning, started, m.cfg.LeaseTTL.Microseconds(), j.ID, StatusQueued, m.owner,
+	)
+	if err != nil {
+		log.Printf("jobs: start %s: %v", j.ID, err)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return
+	}
+
+	// TCP 실행(작업 ID를 request_id로 사용)
+	out := m.tcp.Call(ctx, tcpclient.Req{
+		RequestID: j.ID,
+		UserID:    j.UserID,
+		Type:      "cmd",
+		Cmd:       j.Cmd,
+		TimeoutMs: j.TimeoutMs,
+	})
+
+	// API 종료로 끊긴 실행은 결과 불명
+	if m.ctx.Err() != nil {
+		out.Ok = false
+		out.Code = CodeInterrupted
+		out.Error = "api shut down while the job was running"
+	}
+
+	status := StatusSucceeded
+	if !out.Ok {
+		status = StatusFailed
+	}
+	if err := m.finish(j, status, out); err != nil {
+		log.Printf("jobs: finish %s: %v", j.ID, err)
+	}
+}
+
+// 결과 저장(취소됐거나 소유를 잃은 작업은 덮어쓰지 않음) + 실행 로그
+func (m *Manager) finish(j Job, status string, out tcpclient.Res) error {
+	var exitCode any
+	if out.ExitCode != nil && !out.StartedAt.IsZero() {
+		exitCode = *out.ExitCode
+	}
+
+	_, err := m.db.Exec(
+		`UPDATE jobs SET status=?, finished_at=?, ok=?, code=?, err_msg=?, exit_code=?, duration_ms=?,
+		   output=?, stdout=?, stderr=?, truncated=?, lease_until=NULL
+		 WHERE id=? AND status=? AND claimed_by=?`,
+		status, time.Now(), out.Ok, nullable(out.Code), nullable(out.Error), exitCode, out.DurationMs,
+		out.Output, out.Stdout, out.Stderr, out.Truncated,
+		j.ID, StatusRunning, m.owner,
+	)
+	if err != nil {
+		return err
+	}
+
+	// /run과 같은 실행 로그
+	_, err = m.db.Exec(
+		`INSERT INTO logs(ts, request_id, user_id, cmd, ok, tcp_local, tcp_remote, err_msg, code, exit_code, duration_ms)
+		 VALUES (?,?,?,?,?,?,?,?,?,?,?)`,
+		time.Now(), j.ID, j.UserID, j.Cmd, out.Ok, out.TcpLocal, out.TcpRemote, nullable(out.Error), nullable(out.Code),
+		exitCode, out.DurationMs,
+	)
+	return err
+}
+
+// 취소 함수 제거
+func (m *Manager) forget(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cancel, ok := m.cancels[id]; ok {
+		cancel()
+		delete(m.cancels, id)
+	}
+}
+
+// 작업 조회(다른 사용자의 작업은 없는 것으로 처리)
+func (m *Manager) Get(ctx context.Context, userID, id string) (Job, error) {
+	var (
+		j                      Job
+		started, finished      sql.NullTime
+		ok, exitCode           sql.NullInt64
+		durationMs             sql.NullInt64
+		code, errMsg           sql.NullString
+		output, stdout, stderr sql.NullString
+		truncated              bool
+	)
+	err := m.db.QueryRowContext(ctx,
+		`SELECT id, user_id, cmd, timeout_ms, status, created_at, started_at, finished_at,
+		        ok, code, err_msg, exit_code, duration_ms, output, stdout, stderr, truncated
+		 FROM jobs WHERE id=? AND user_id=?`, id, userID,
+	).Scan(&j.ID, &j.UserID, &j.Cmd, &j.TimeoutMs, &j.Status, &j.CreatedAt, &started, &finished,
+		&ok, &code, &errMsg, &exitCode, &durationMs, &output, &stdout, &stderr, &truncated)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Job{}, ErrNotFound
+	}
+	if err != nil {
+		return Job{}, err
+	}
+
+	if started.Valid {
+		j.StartedAt = &started.Time
+	}
+	if finished.Valid {
+		j.FinishedAt = &finished.Time
+	}
+
+	// 끝난 작업만 결과 포함
+	if j.Finished() {
+		r := &tcpclient.Res{
+			Ok:         ok.Int64 == 1,
+			Output:     output.String,
+			Stdout:     stdout.String,
+			Stderr:     stderr.String,
+			Error:      errMsg.String,
+			Code:       code.String,
+			Truncated:  truncated,
+			DurationMs: durationMs.Int64,
+			RequestID:  j.ID,
+			UserID:     j.UserID,
+		}
+		if exitCode.Valid {
+			c := int(exitCode.Int64)
+			r.ExitCode = &c
+		}
+		if started.Valid {
+			r.StartedAt = started.Time
+		}
+		j.Result = r
+	}
+	return j, nil
+}
+
+// 작업 취소(대기/실행중만 가능)
+func (m *Manager) Cancel(ctx context.Context, userID, id string) (Job, error) {
+	res, err := m.db.ExecContext(ctx,
+		`UPDATE jobs SET status=?, finished_at=?, ok=0, code=?, err_msg=?
+		 WHERE id=? AND user_id=? AND status IN (?,?)`,
+		StatusCancelled, time.Now(), tcpclient.CodeCancelled, "cancelled by user",
+		id, userID, StatusQueued, StatusRunning,
+	)
+	if err != nil {
+		return Job{}, err
+	}
+	n, _ := res.RowsAffected()
+
+	// 이 프로세스에서 실행중이면 중단
+	m.mu.Lock()
+	cancel := m.cancels[id]
+	m.mu.Unlock()
+	if cancel != nil && n > 0 {
+		cancel()
+	}
+
+	j, err := m.Get(ctx, userID, id)
+	if err != nil {
+		return Job{}, err
+	}
+	if n == 0 {
+		return j, ErrFinished
+	}
+	return j, nil
+}
+
+// 종료: 새 작업 거절 후 실행중 작업을 ctx까지 기다리고 남은 작업은 중단
+// - 대기중 작업은 소유를 풀어 queued로 남김(다음 기동 또는 다른 인스턴스가 실행)
+func (m *Manager) Close(ctx context.Context) {
+	m.mu.Lock()
+	if !m.closed {
+		m.closed = true
+		close(m.quit)
+	}
+	m.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		m.stop()
+		<-done
+	}
+	// 갱신 루프는 실행중 작업이 끝난 뒤 중단
+	m.stop()
+	m.leases.Wait()
+
+	// 대기중 작업 소유 해제(소유 기간을 기다리지 않고 바로 인계)
+	rctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := m.db.ExecContext(rctx,
+		`UPDATE jobs SET claimed_by=NULL, lease_until=NULL WHERE claimed_by=? AND status=?`,
+		m.owner, StatusQueued,
+	); err != nil {
+		log.Printf("jobs: release queued jobs: %v", err)
+	}
+}
+
+// 빈 문자열은 NULL
+func nullable(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// 인스턴스 ID(호스트 + pid + 랜덤)
+func newOwner() string {
+	host, _ := os.Hostname()
+	if len(host) > 32 {
+		host = host[:32]
+	}
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), newID()[:8])
+}
+
+// 작업 ID 생성
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"net/http"
+
+	"golang-network-labs/api/internal/problem"
 )
 
 // 동시 실행 제한 미들웨어(세마포어 방식)
@@ -22,7 +24,7 @@ func ConcurrencyLimit(max int) func(http.Handler) http.Handler {
 				defer func() { <-sem }()
 			default:
 				// 슬롯 부족이면 429
-				http.Error(w, "too many requests", http.StatusTooManyRequests) // 429
+				problem.Error(w, r, problem.CodeConcurrencyLimited, "too many requests")
 				return
 			}
 
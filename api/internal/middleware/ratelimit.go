@@ -3,14 +3,30 @@ package middleware
 import (
 	"net"
 	"net/http"
+	"net/netip"
+	"strings"
 	"sync"
 	"time"
 
+	"golang-network-labs/api/internal/auth"
+	"golang-network-labs/api/internal/problem"
+
 	"golang.org/x/time/rate"
 )
 
-// IP별 레이트리밋
-func RateLimitPerIP(rps float64, burst int) func(http.Handler) http.Handler {
+// 호출자별 레이트리밋(인증된 사용자는 사용자 단위, 익명은 IP 단위)
+// - trusted: X-Forwarded-For를 믿을 프록시 대역(비면 RemoteAddr만 사용)
+func RateLimitPerClient(rps float64, burst int, trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return rateLimit(rps, burst, func(r *http.Request) string { return clientKey(r, trusted) })
+}
+
+// IP별 레이트리밋(인증 앞에 두어 잘못된 자격 증명 반복으로 DB/검증을 두드리는 것 방지)
+func RateLimitPerIP(rps float64, burst int, trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return rateLimit(rps, burst, func(r *http.Request) string { return "ip:" + clientIP(r, trusted) })
+}
+
+// 키별 토큰 버킷 레이트리밋
+func rateLimit(rps float64, burst int, keyOf func(*http.Request) string) func(http.Handler) http.Handler {
 	// 클라이언트 상태
 	type client struct {
 		limiter  *rate.Limiter
@@ -30,10 +46,10 @@ func RateLimitPerIP(rps float64, burst int) func(http.Handler) http.Handler {
 			time.Sleep(1 * time.Minute)
 
 			mu.Lock()
-			for ip, c := range clients {
+			for key, c := range clients {
 				// 5분 이상 미사용이면 제거
 				if time.Since(c.lastSeen) > 5*time.Minute {
-					delete(clients, ip)
+					delete(clients, key)
 				}
 			}
 			mu.Unlock()
@@ -42,15 +58,15 @@ func RateLimitPerIP(rps float64, burst int) func(http.Handler) http.Handler {
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// IP 추출
-			ip := clientIP(r)
+			// 호출자 키
+			key := keyOf(r)
 
 			// limiter 조회/생성
 			mu.Lock()
-			c, ok := clients[ip]
+			c, ok := clients[key]
 			if !ok {
 				c = &client{limiter: rate.NewLimiter(rate.Limit(rps), burst)}
-				clients[ip] = c
+				clients[key] = c
 			}
 			// 마지막 접근 갱신
 			c.lastSeen = time.Now()
@@ -59,7 +75,7 @@ func RateLimitPerIP(rps float64, burst int) func(http.Handler) http.Handler {
 
 			// 토큰 없으면 거절
 			if !lim.Allow() {
-				http.Error(w, "too many requests", http.StatusTooManyRequests)
+				problem.Error(w, r, problem.CodeRateLimited, "too many requests")
 				return
 			}
 
@@ -69,24 +85,52 @@ func RateLimitPerIP(rps float64, burst int) func(http.Handler) http.Handler {
 	}
 }
 
-// 클라이언트 IP 추출
-func clientIP(r *http.Request) string {
-	// 프록시 환경 고려
-	xff := r.Header.Get("X-Forwarded-For")
-	if xff != "" {
-		// 첫 IP만 사용
-		for i := 0; i < len(xff); i++ {
-			if xff[i] == ',' {
-				return xff[:i]
-			}
-		}
-		return xff
+// 레이트리밋 키(인증 미들웨어 뒤에서 사용)
+func clientKey(r *http.Request, trusted []netip.Prefix) string {
+	if p, ok := auth.FromContext(r.Context()); ok && p.Method != auth.MethodAnonymous {
+		return "user:" + p.ID
 	}
+	return "ip:" + clientIP(r, trusted)
+}
 
+// 클라이언트 IP 추출
+// - 직접 연결한 주소가 신뢰 프록시일 때만 X-Forwarded-For 사용
+// - 오른쪽(가까운 홉)부터 신뢰 프록시를 건너뛰고 처음 나오는 주소(클라이언트가 보낸 왼쪽 값은 무시)
+func clientIP(r *http.Request, trusted []netip.Prefix) string {
 	// RemoteAddr 분해
 	host, _, err := net.SplitHostPort(r.RemoteAddr)
 	if err != nil {
-		return r.RemoteAddr
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !isTrusted(addr, trusted) {
+		return host
+	}
+
+	// 여러 헤더는 순서대로 이어 붙인 목록과 같음
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	ip := addr
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// 해석할 수 없는 값 앞은 믿지 않음
+			break
+		}
+		ip = hop.Unmap()
+		if !isTrusted(ip, trusted) {
+			break
+		}
+	}
+	return ip.String()
+}
+
+// 신뢰 프록시 대역에 속하는지
+func isTrusted(addr netip.Addr, trusted []netip.Prefix) bool {
+	addr = addr.Unmap()
+	for _, p := range trusted {
+		if p.Contains(addr) {
+			return true
+		}
 	}
-	return host
+	return false
 }
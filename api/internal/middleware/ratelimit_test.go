@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.168.1.5/32")}
+
+	cases := []struct {
+		name    string
+		remote  string
+		xff     []string
+		trusted []netip.Prefix
+		want    string
+	}{
+		{"no proxy", "203.0.113.7:5000", nil, trusted, "203.0.113.7"},
+		// 신뢰 프록시가 아니면 헤더 무시(매 요청 새 값으로 버킷 우회 불가)
+		{"spoofed header", "203.0.113.7:5000", []string{"1.2.3.4"}, trusted, "203.0.113.7"},
+		{"no trusted list", "10.0.0.1:5000", []string{"1.2.3.4"}, nil, "10.0.0.1"},
+		{"trusted proxy", "10.0.0.1:5000", []string{"198.51.100.9"}, trusted, "198.51.100.9"},
+		// 클라이언트가 보낸 왼쪽 값은 무시하고 가장 오른쪽의 비신뢰 홉
+		{"spoof through proxy", "10.0.0.1:5000", []string{"1.2.3.4, 198.51.100.9"}, trusted, "198.51.100.9"},
+		{"proxy chain", "10.0.0.1:5000", []string{"198.51.100.9, 192.168.1.5, 10.2.3.4"}, trusted, "198.51.100.9"},
+		{"multiple headers", "10.0.0.1:5000", []string{"1.2.3.4", "198.51.100.9"}, trusted, "198.51.100.9"},
+		{"garbage hop", "10.0.0.1:5000", []string{"198.51.100.9, nonsense"}, trusted, "10.0.0.1"},
+		{"trusted without header", "10.0.0.1:5000", nil, trusted, "10.0.0.1"},
+		{"all trusted", "10.0.0.1:5000", []string{"10.9.9.9"}, trusted, "10.9.9.9"},
+		{"ipv6 remote", "[2001:db8::1]:5000", []string{"1.2.3.4"}, trusted, "2001:db8::1"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tc.remote
+			for _, v := range tc.xff {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if got := clientIP(r, tc.trusted); got != tc.want {
+				t.Fatalf("clientIP = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
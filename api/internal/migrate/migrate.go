@@ -0,0 +1,390 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 내장 마이그레이션 파일
+//
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+// 버전 기록 테이블
+const versionTable = "schema_migrations"
+
+// 동시 실행 방지 락 이름
+const lockName = "golang_network_labs_migrate"
+
+// 락 대기 시간(초)
+const lockTimeoutSec = 30
+
+// dirty 스키마 에러
+var ErrDirty = errors.New("schema is dirty")
+
+// 바이너리보다 새 스키마 에러
+var ErrNewerSchema = errors.New("schema is newer than this binary")
+
+// 마이그레이션 한 건
+type Migration struct {
+	// 버전 번호
+	Version int
+	// 이름(파일명 기준)
+	Name string
+	// up SQL
+	Up string
+	// down SQL
+	Down string
+}
+
+// 현재 스키마 상태
+type Status struct {
+	// 적용된 최신 버전(없으면 0)
+	Current int
+	// dirty 여부
+	Dirty bool
+	// 바이너리가 아는 최신 버전
+	Latest int
+	// 미적용 목록
+	Pending []Migration
+}
+
+// 마이그레이터 본체
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// 마이그레이터 생성
+func New(db *sql.DB) (*Migrator, error) {
+	// 내장 파일 로드
+	ms, err := load(migrationFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: ms}, nil
+}
+
+// 내장 파일 → 버전순 목록
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// 버전별 묶음
+	byVersion := make(map[int]*Migration)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		// 파일명: 0001_name.up.sql / 0001_name.down.sql
+		file := e.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(file, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(file, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("migrate: unexpected file %q", file)
+		}
+
+		// 버전 파싱
+		base := strings.TrimSuffix(file, "."+direction+".sql")
+		num, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migrate: bad file name %q", file)
+		}
+		version, err := strconv.Atoi(num)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migrate: bad version in %q", file)
+		}
+
+		// 내용 읽기
+		body, err := fs.ReadFile(fsys, path.Join(dir, file))
+		if err != nil {
+			return nil, err
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("migrate: version %d has conflicting names", version)
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	// 정렬 + 짝 검사
+	out := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			return nil, fmt.Errorf("migrate: version %d needs both up and down", m.Version)
+		}
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+
+	// 번호 연속성 검사
+	for i, m := range out {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migrate: missing version %d", i+1)
+		}
+	}
+	return out, nil
+}
+
+// 바이너리가 아는 최신 버전
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// 상태 조회(읽기 전용, 버전 테이블이 없으면 전부 미적용)
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+	defer conn.Close()
+
+	ok, err := tableExists(ctx, conn, versionTable)
+	if err != nil {
+		return Status{}, err
+	}
+	if !ok {
+		return Status{Latest: m.Latest(), Pending: m.migrations}, nil
+	}
+	return m.status(ctx, conn)
+}
+
+// 미적용 마이그레이션 전부 적용
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	conn, unlock, err := m.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	// 시작 전 상태 검사
+	st, err := m.status(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+	if err := st.check(); err != nil {
+		return 0, err
+	}
+
+	// 순서대로 적용
+	applied := 0
+	for _, mg := range st.Pending {
+		if err := m.apply(ctx, conn, mg, true); err != nil {
+			return applied, err
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+// 최신 버전부터 steps개 되돌리기
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	if steps <= 0 {
+		return 0, nil
+	}
+
+	conn, unlock, err := m.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	// 시작 전 상태 검사
+	st, err := m.status(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+	if err := st.check(); err != nil {
+		return 0, err
+	}
+
+	// 역순으로 되돌리기
+	reverted := 0
+	for v := st.Current; v > 0 && reverted < steps; v-- {
+		if err := m.apply(ctx, conn, m.migrations[v-1], false); err != nil {
+			return reverted, err
+		}
+		reverted++
+	}
+	return reverted, nil
+}
+
+// dirty/새 스키마 거절
+func (st Status) check() error {
+	if st.Dirty {
+		return fmt.Errorf("%w: version %d failed midway, fix it manually", ErrDirty, st.Current)
+	}
+	if st.Current > st.Latest {
+		return fmt.Errorf("%w: db=%d binary=%d", ErrNewerSchema, st.Current, st.Latest)
+	}
+	return nil
+}
+
+// 현재 상태 계산
+func (m *Migrator) status(ctx context.Context, conn *sql.Conn) (Status, error) {
+	st := Status{Latest: m.Latest()}
+
+	// 최신 버전 + dirty 조회
+	var current sql.NullInt64
+	var dirty sql.NullInt64
+	err := conn.QueryRowContext(ctx,
+		`SELECT version, dirty FROM `+versionTable+` ORDER BY version DESC LIMIT 1`,
+	).Scan(&current, &dirty)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return st, err
+	}
+	st.Current = int(current.Int64)
+	st.Dirty = dirty.Int64 != 0
+
+	// 미적용 목록
+	for _, mg := range m.migrations {
+		if mg.Version > st.Current {
+			st.Pending = append(st.Pending, mg)
+		}
+	}
+	return st, nil
+}
+
+// 한 건 적용(up/down)
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mg Migration, up bool) error {
+	body := mg.Down
+	if up {
+		body = mg.Up
+		// 적용 전 dirty 기록
+		if _, err := conn.ExecContext(ctx,
+			`INSERT INTO `+versionTable+`(version, name, dirty, applied_at) VALUES (?,?,1,?)`,
+			mg.Version, mg.Name, time.Now(),
+		); err != nil {
+			return err
+		}
+	} else {
+		// 되돌리기 전 dirty 표시
+		if _, err := conn.ExecContext(ctx,
+			`UPDATE `+versionTable+` SET dirty = 1 WHERE version = ?`, mg.Version,
+		); err != nil {
+			return err
+		}
+	}
+
+	// DDL은 트랜잭션이 안 되므로 문장 단위 실행
+	for _, stmt := range splitStatements(body) {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("migrate: version %d (%s): %w", mg.Version, mg.Name, err)
+		}
+	}
+
+	// 성공 기록
+	if up {
+		_, err := conn.ExecContext(ctx,
+			`UPDATE `+versionTable+` SET dirty = 0 WHERE version = ?`, mg.Version,
+		)
+		return err
+	}
+	_, err := conn.ExecContext(ctx, `DELETE FROM `+versionTable+` WHERE version = ?`, mg.Version)
+	return err
+}
+
+// 락 + 테이블 준비
+func (m *Migrator) lock(ctx context.Context) (*sql.Conn, func(), error) {
+	// 락은 세션 단위라 커넥션 고정
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 여러 api 인스턴스 동시 기동 대비
+	var got sql.NullInt64
+	if err := conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, ?)`, lockName, lockTimeoutSec).Scan(&got); err != nil {
+		_ = conn.Close()
+		return nil, nil, err
+	}
+	if got.Int64 != 1 {
+		_ = conn.Close()
+		return nil, nil, errors.New("migrate: could not acquire lock")
+	}
+
+	unlock := func() {
+		_, _ = conn.ExecContext(context.Background(), `SELECT RELEASE_LOCK(?)`, lockName)
+		_ = conn.Close()
+	}
+
+	if err := ensureTable(ctx, conn); err != nil {
+		unlock()
+		return nil, nil, err
+	}
+	return conn, unlock, nil
+}
+
+// 현재 DB에 테이블이 있는지
+func tableExists(ctx context.Context, conn *sql.Conn, name string) (bool, error) {
+	var n int
+	err := conn.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?`, name,
+	).Scan(&n)
+	return n > 0, err
+}
+
+// 버전 테이블 생성
+func ensureTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+versionTable+` (
+  version INT NOT NULL PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  dirty TINYINT NOT NULL,
+  applied_at DATETIME(3) NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`)
+	return err
+}
+
+// 세미콜론 기준 문장 분리
+func splitStatements(body string) []string {
+	var out []string
+	var cur strings.Builder
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		// 주석/빈 줄 무시
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		cur.WriteString(line)
+		cur.WriteString("\n")
+		// 줄 끝 세미콜론이면 문장 종료
+		if strings.HasSuffix(trimmed, ";") {
+			stmt := strings.TrimSuffix(strings.TrimSpace(cur.String()), ";")
+			out = append(out, stmt)
+			cur.Reset()
+		}
+	}
+	// 세미콜론 없는 마지막 문장
+	if rest := strings.TrimSpace(cur.String()); rest != "" {
+		out = append(out, rest)
+	}
+	return out
+}
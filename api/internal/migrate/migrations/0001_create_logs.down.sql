@@ -0,0 +1,9 @@
+-- 이 마이그레이션이 만든 테이블만 삭제(손으로 만든 기존 logs는 데이터 보존)
+SET @drop_logs = COALESCE((
+  SELECT IF(table_comment = 'migrate:0001', 'DROP TABLE logs', 'DO 0')
+  FROM information_schema.tables
+  WHERE table_schema = DATABASE() AND table_name = 'logs'
+), 'DO 0');
+PREPARE drop_logs FROM @drop_logs;
+EXECUTE drop_logs;
+DEALLOCATE PREPARE drop_logs;
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS logs (
+  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+  ts DATETIME(3) NOT NULL,
+  request_id VARCHAR(64) NOT NULL,
+  user_id VARCHAR(128) NOT NULL,
+  cmd VARCHAR(1024) NOT NULL,
+  ok TINYINT NOT NULL,
+  tcp_local VARCHAR(64) NULL,
+  tcp_remote VARCHAR(64) NULL,
+  err_msg TEXT NULL,
+  KEY idx_logs_ts (ts),
+  KEY idx_logs_request_id (request_id),
+  KEY idx_logs_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='migrate:0001';
+-- 마이그레이션 이전에 손으로 만든 logs는 그대로 두고 빠진 컬럼/인덱스만 추가
+ALTER TABLE logs
+  MODIFY COLUMN ts DATETIME(3) NOT NULL,
+  ADD COLUMN IF NOT EXISTS request_id VARCHAR(64) NOT NULL DEFAULT '' AFTER ts,
+  ADD COLUMN IF NOT EXISTS user_id VARCHAR(128) NOT NULL DEFAULT '' AFTER request_id,
+  MODIFY COLUMN cmd VARCHAR(1024) NOT NULL,
+  ADD COLUMN IF NOT EXISTS tcp_local VARCHAR(64) NULL AFTER ok,
+  ADD COLUMN IF NOT EXISTS tcp_remote VARCHAR(64) NULL AFTER tcp_local,
+  ADD COLUMN IF NOT EXISTS err_msg TEXT NULL AFTER tcp_remote,
+  ADD KEY IF NOT EXISTS idx_logs_ts (ts),
+  ADD KEY IF NOT EXISTS idx_logs_request_id (request_id),
+  ADD KEY IF NOT EXISTS idx_logs_user_id (user_id);
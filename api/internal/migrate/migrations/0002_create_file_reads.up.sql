@@ -0,0 +1,13 @@
+CREATE TABLE file_reads (
+  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+  ts DATETIME(3) NOT NULL,
+  request_id VARCHAR(64) NOT NULL,
+  user_id VARCHAR(128) NOT NULL,
+  file_path VARCHAR(1024) NOT NULL,
+  file_offset BIGINT NOT NULL,
+  limit_size BIGINT NOT NULL,
+  ok TINYINT NOT NULL,
+  err_msg TEXT NULL,
+  KEY idx_file_reads_ts (ts),
+  KEY idx_file_reads_request_id (request_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,10 @@
+CREATE TABLE url_results (
+  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+  ts DATETIME(3) NOT NULL,
+  request_id VARCHAR(64) NOT NULL,
+  user_id VARCHAR(128) NOT NULL,
+  url VARCHAR(2048) NOT NULL,
+  title VARCHAR(1024) NOT NULL,
+  KEY idx_url_results_ts (ts),
+  KEY idx_url_results_request_id (request_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
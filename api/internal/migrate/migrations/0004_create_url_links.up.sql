@@ -0,0 +1,8 @@
+CREATE TABLE url_links (
+  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+  result_id BIGINT NOT NULL,
+  link_url VARCHAR(2048) NOT NULL,
+  KEY idx_url_links_result_id (result_id),
+  CONSTRAINT fk_url_links_result FOREIGN KEY (result_id)
+    REFERENCES url_results (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
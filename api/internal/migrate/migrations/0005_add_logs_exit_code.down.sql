@@ -0,0 +1,3 @@
+ALTER TABLE logs
+  DROP COLUMN duration_ms,
+  DROP COLUMN exit_code;
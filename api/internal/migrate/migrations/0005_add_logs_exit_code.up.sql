@@ -0,0 +1,3 @@
+ALTER TABLE logs
+  ADD COLUMN exit_code INT NULL AFTER err_msg,
+  ADD COLUMN duration_ms BIGINT NULL AFTER exit_code;
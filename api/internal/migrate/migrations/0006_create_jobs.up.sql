@@ -0,0 +1,21 @@
+CREATE TABLE jobs (
+  id VARCHAR(64) PRIMARY KEY,
+  user_id VARCHAR(128) NOT NULL,
+  cmd VARCHAR(1024) NOT NULL,
+  timeout_ms BIGINT NOT NULL DEFAULT 0,
+  status VARCHAR(16) NOT NULL,
+  created_at DATETIME(3) NOT NULL,
+  started_at DATETIME(3) NULL,
+  finished_at DATETIME(3) NULL,
+  ok TINYINT NULL,
+  code VARCHAR(64) NULL,
+  err_msg TEXT NULL,
+  exit_code INT NULL,
+  duration_ms BIGINT NULL,
+  output MEDIUMTEXT NULL,
+  stdout MEDIUMTEXT NULL,
+  stderr MEDIUMTEXT NULL,
+  truncated TINYINT NOT NULL DEFAULT 0,
+  KEY idx_jobs_user_id (user_id, created_at),
+  KEY idx_jobs_status (status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
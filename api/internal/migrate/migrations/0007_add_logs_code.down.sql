@@ -0,0 +1,3 @@
+ALTER TABLE logs
+  DROP KEY idx_logs_code,
+  DROP COLUMN code;
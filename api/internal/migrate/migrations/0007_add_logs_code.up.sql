@@ -0,0 +1,3 @@
+ALTER TABLE logs
+  ADD COLUMN code VARCHAR(64) NULL AFTER err_msg,
+  ADD KEY idx_logs_code (code);
@@ -0,0 +1,14 @@
+CREATE TABLE api_keys (
+  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+  prefix VARCHAR(16) NOT NULL,
+  key_hash CHAR(64) NOT NULL,
+  user_id VARCHAR(128) NOT NULL,
+  name VARCHAR(128) NOT NULL DEFAULT '',
+  created_at DATETIME(3) NOT NULL,
+  expires_at DATETIME(3) NULL,
+  revoked_at DATETIME(3) NULL,
+  last_used_at DATETIME(3) NULL,
+  UNIQUE KEY uq_api_keys_hash (key_hash),
+  UNIQUE KEY uq_api_keys_prefix (prefix),
+  KEY idx_api_keys_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
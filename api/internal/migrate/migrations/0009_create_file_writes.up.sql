@@ -0,0 +1,14 @@
+CREATE TABLE file_writes (
+  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+  ts DATETIME(3) NOT NULL,
+  request_id VARCHAR(64) NOT NULL,
+  user_id VARCHAR(128) NOT NULL,
+  file_path VARCHAR(1024) NOT NULL,
+  size_bytes BIGINT NOT NULL,
+  sha256 CHAR(64) NULL,
+  ok TINYINT NOT NULL,
+  err_msg TEXT NULL,
+  code VARCHAR(64) NULL,
+  KEY idx_file_writes_ts (ts),
+  KEY idx_file_writes_request_id (request_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,2 @@
+ALTER TABLE logs
+  MODIFY COLUMN cmd VARCHAR(1024) NOT NULL;
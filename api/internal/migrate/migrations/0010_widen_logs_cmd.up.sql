@@ -0,0 +1,2 @@
+ALTER TABLE logs
+  MODIFY COLUMN cmd TEXT NOT NULL;
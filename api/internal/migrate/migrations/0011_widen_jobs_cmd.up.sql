@@ -0,0 +1,2 @@
+ALTER TABLE jobs
+  MODIFY COLUMN cmd TEXT NOT NULL;
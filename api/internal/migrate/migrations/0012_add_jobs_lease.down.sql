@@ -0,0 +1,4 @@
+ALTER TABLE jobs
+  DROP KEY idx_jobs_lease,
+  DROP COLUMN lease_until,
+  DROP COLUMN claimed_by;
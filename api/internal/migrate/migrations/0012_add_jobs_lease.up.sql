@@ -0,0 +1,4 @@
+ALTER TABLE jobs
+  ADD COLUMN claimed_by VARCHAR(64) NULL,
+  ADD COLUMN lease_until DATETIME(3) NULL,
+  ADD KEY idx_jobs_lease (status, lease_until);
@@ -0,0 +1,153 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"golang-network-labs/api/internal/tcpclient"
+)
+
+// RFC 7807 미디어 타입
+const ContentType = "application/problem+json"
+
+// type URI 접두사(코드별 고유 URI)
+const typePrefix = "urn:golang-network-labs:problem:"
+
+// API 자체 에러 코드(TCP 쪽 코드는 tcpclient.Code*)
+const (
+	// 지원하지 않는 메서드
+	CodeMethodNotAllowed = "METHOD_NOT_ALLOWED"
+	// 지원하지 않는 Content-Type
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	// IP당 요청 속도 초과
+	CodeRateLimited = "RATE_LIMITED"
+	// 동시 실행 수 초과
+	CodeConcurrencyLimited = "CONCURRENCY_LIMITED"
+	// 외부 URL 조회 실패
+	CodeFetchFailed = "FETCH_FAILED"
+	// 이미 끝난 작업 취소
+	CodeJobFinished = "JOB_FINISHED"
+	// 자격 증명 없음/무효
+	CodeUnauthenticated = "UNAUTHENTICATED"
+	// 자격 증명 확인 불가(DB 장애)
+	CodeAuthUnavailable = "AUTH_UNAVAILABLE"
+)
+
+// 코드별 HTTP 상태 + 제목
+type kind struct {
+	status int
+	title  string
+}
+
+// 코드 → 상태(목록에 없으면 500)
+var kinds = map[string]kind{
+	// 요청 문제
+	tcpclient.CodeBadRequest:         {http.StatusBadRequest, "Bad request"},
+	CodeUnauthenticated:              {http.StatusUnauthorized, "Authentication required"},
+	CodeMethodNotAllowed:             {http.StatusMethodNotAllowed, "Method not allowed"},
+	CodeUnsupportedMediaType:         {http.StatusUnsupportedMediaType, "Unsupported media type"},
+	tcpclient.CodeFrameTooLarge:      {http.StatusRequestEntityTooLarge, "Request too large"},
+	CodeJobFinished:                  {http.StatusConflict, "Job already finished"},
+	tcpclient.CodeDuplicateRequestID: {http.StatusConflict, "Duplicate request id"},
+	tcpclient.CodeOffsetMismatch:     {http.StatusConflict, "Upload offset mismatch"},
+	tcpclient.CodeFileTooLarge:       {http.StatusRequestEntityTooLarge, "File too large"},
+	tcpclient.CodeChecksumMismatch:   {http.StatusUnprocessableEntity, "Checksum mismatch"},
+
+	// 정책/권한 거절
+	tcpclient.CodeForbidden:          {http.StatusForbidden, "Not permitted for this user"},
+	tcpclient.CodeCmdNotAllowed:      {http.StatusForbidden, "Command not allowed"},
+	tcpclient.CodeArgNotAllowed:      {http.StatusForbidden, "Argument not allowed"},
+	tcpclient.CodePathEscape:         {http.StatusForbidden, "Path outside the allowed root"},
+	tcpclient.CodePermissionDenied:   {http.StatusForbidden, "Permission denied"},
+	tcpclient.CodeReadOnly:           {http.StatusForbidden, "File root is read-only"},
+	tcpclient.CodeNotFound:           {http.StatusNotFound, "Not found"},
+	tcpclient.CodeIOError:            {http.StatusInternalServerError, "I/O error"},
+	tcpclient.CodeTimeout:            {http.StatusGatewayTimeout, "Command timed out"},
+	tcpclient.CodeKilled:             {http.StatusInternalServerError, "Command killed"},
+	tcpclient.CodeCancelled:          {http.StatusServiceUnavailable, "Request cancelled"},
+	tcpclient.CodeInternal:           {http.StatusInternalServerError, "Internal error"},
+	CodeFetchFailed:                  {http.StatusBadGateway, "Fetching the URL failed"},
+	tcpclient.CodeUnsupportedType:    {http.StatusBadGateway, "Backend does not support the request"},
+	tcpclient.CodeUnsupportedVersion: {http.StatusBadGateway, "Backend protocol version mismatch"},
+	tcpclient.CodeProtocolError:      {http.StatusBadGateway, "Backend protocol error"},
+	tcpclient.CodeUnauthorized:       {http.StatusBadGateway, "Backend rejected the API's credentials"},
+	tcpclient.CodeIncompatible:       {http.StatusBadGateway, "Incompatible backend"},
+	tcpclient.CodeBackendUnavailable: {http.StatusBadGateway, "Backend unavailable"},
+	tcpclient.CodeBackendTimeout:     {http.StatusGatewayTimeout, "Backend timed out"},
+
+	// 과부하(Retry-After)
+	CodeRateLimited:            {http.StatusTooManyRequests, "Too many requests"},
+	CodeConcurrencyLimited:     {http.StatusTooManyRequests, "Too many concurrent requests"},
+	tcpclient.CodeQueueFull:    {http.StatusServiceUnavailable, "Execution queue full"},
+	tcpclient.CodeQueueTimeout: {http.StatusServiceUnavailable, "Execution queue wait timed out"},
+	CodeAuthUnavailable:        {http.StatusServiceUnavailable, "Authentication unavailable"},
+}
+
+// 재시도 가능 코드
+var retryable = map[string]bool{
+	CodeRateLimited:            true,
+	CodeConcurrencyLimited:     true,
+	tcpclient.CodeQueueFull:    true,
+	tcpclient.CodeQueueTimeout: true,
+	CodeAuthUnavailable:        true,
+}
+
+// 문제 응답 본문
+type Problem struct {
+	// 표준 필드
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// 확장: 안정 에러 코드
+	Code string `json:"code"`
+	// 확장: 추적용 ID
+	RequestID string `json:"request_id,omitempty"`
+	// 확장: TCP 실행 결과(timeout 등 부분 결과)
+	Result any `json:"result,omitempty"`
+}
+
+// 코드에 맞는 HTTP 상태
+func Status(code string) int {
+	if k, ok := kinds[code]; ok {
+		return k.status
+	}
+	return http.StatusInternalServerError
+}
+
+// 문제 응답 생성
+func New(code, detail string) Problem {
+	k, ok := kinds[code]
+	if !ok {
+		k = kind{http.StatusInternalServerError, "Internal error"}
+	}
+	return Problem{
+		Type:   typePrefix + strings.ReplaceAll(strings.ToLower(code), "_", "-"),
+		Title:  k.title,
+		Status: k.status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// 문제 응답 작성
+func Write(w http.ResponseWriter, r *http.Request, p Problem) {
+	if p.Instance == "" {
+		p.Instance = r.URL.Path
+	}
+	if retryable[p.Code] {
+		w.Header().Set("Retry-After", "1")
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// 코드 + 메시지로 바로 작성
+func Error(w http.ResponseWriter, r *http.Request, code, detail string) {
+	Write(w, r, New(code, detail))
+}
@@ -0,0 +1,159 @@
+package tcpclient
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"time"
+)
+
+// 전송 방식
+const (
+	// 길이 prefix 프레임(바이너리 payload 지원)
+	FramingFrame = "frame"
+	// JSON 한 줄(기존 방식)
+	FramingLine = "line"
+)
+
+// 프레임 모드 핸드셰이크 바이트(서버와 동일)
+const frameMagic byte = 0xF7
+
+// 메시지 최대 크기 기본값
+const defaultMaxFrame = 4 << 20
+
+// 프레임 앞 길이 필드 크기
+const frameHeaderSize = 8
+
+// 크기 초과 에러
+var errFrameTooLarge = errors.New("frame too large")
+
+// 메시지 한 건(JSON 헤더 + 선택적 바이너리)
+type message struct {
+	header  []byte
+	payload []byte
+}
+
+// 연결 단위 직렬화 방식
+type codec interface {
+	readMessage() (message, error)
+	writeMessage(message) error
+	binary() bool
+}
+
+// 클라이언트: 연결 직후 모드 협상
+// - 프레임 모드면 FrameMagic 전송 후 같은 바이트 응답을 확인
+func clientCodec(conn net.Conn, framing string, max int, timeout time.Duration) (codec, error) {
+	if max <= 0 {
+		max = defaultMaxFrame
+	}
+	br := bufio.NewReader(conn)
+
+	if framing == FramingLine {
+		return &lineCodec{r: br, w: conn, max: max}, nil
+	}
+
+	// 핸드셰이크(응답 대기 제한)
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+	defer conn.SetDeadline(time.Time{})
+
+	if _, err := conn.Write([]byte{frameMagic}); err != nil {
+		return nil, err
+	}
+	b, err := br.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if b != frameMagic {
+		return nil, errors.New("tcp server does not support frame mode")
+	}
+	return &frameCodec{r: br, w: conn, max: max}, nil
+}
+
+// JSON 한 줄 = 메시지 한 건
+type lineCodec struct {
+	r   *bufio.Reader
+	w   io.Writer
+	max int
+}
+
+// 크기 제한 있는 한 줄 읽기
+func (c *lineCodec) readMessage() (message, error) {
+	var line []byte
+	for {
+		chunk, err := c.r.ReadSlice('\n')
+		if len(line)+len(chunk) > c.max+1 {
+			return message{}, errFrameTooLarge
+		}
+		line = append(line, chunk...)
+		if err == nil {
+			return message{header: line[:len(line)-1]}, nil
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return message{}, err
+		}
+	}
+}
+
+// JSON + '\n' 한 줄 쓰기
+func (c *lineCodec) writeMessage(m message) error {
+	if len(m.payload) > 0 {
+		return errors.New("binary payload needs frame mode")
+	}
+	if len(m.header) > c.max {
+		return errFrameTooLarge
+	}
+	_, err := c.w.Write(append(m.header, '\n'))
+	return err
+}
+
+func (c *lineCodec) binary() bool { return false }
+
+// [헤더 길이 u32][payload 길이 u32][JSON 헤더][payload]
+type frameCodec struct {
+	r   io.Reader
+	w   io.Writer
+	max int
+}
+
+// 프레임 한 건 읽기
+func (c *frameCodec) readMessage() (message, error) {
+	var lens [frameHeaderSize]byte
+	if _, err := io.ReadFull(c.r, lens[:]); err != nil {
+		return message{}, err
+	}
+	hl := binary.BigEndian.Uint32(lens[0:4])
+	pl := binary.BigEndian.Uint32(lens[4:8])
+
+	// 할당 전에 크기 검사
+	if uint64(hl)+uint64(pl) > uint64(c.max) {
+		return message{}, errFrameTooLarge
+	}
+
+	buf := make([]byte, int(hl)+int(pl))
+	if _, err := io.ReadFull(c.r, buf); err != nil {
+		return message{}, err
+	}
+	m := message{header: buf[:hl]}
+	if pl > 0 {
+		m.payload = buf[hl:]
+	}
+	return m, nil
+}
+
+// 프레임 한 건 쓰기
+func (c *frameCodec) writeMessage(m message) error {
+	if len(m.header)+len(m.payload) > c.max {
+		return errFrameTooLarge
+	}
+	buf := make([]byte, frameHeaderSize, frameHeaderSize+len(m.header)+len(m.payload))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(m.header)))
+	binary.BigEndian.PutUint32(buf[4:8], uint32(len(m.payload)))
+	buf = append(buf, m.header...)
+	buf = append(buf, m.payload...)
+	_, err := c.w.Write(buf)
+	return err
+}
+
+func (c *frameCodec) binary() bool { return true }
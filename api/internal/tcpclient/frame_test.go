@@ -0,0 +1,191 @@
+package tcpclient
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 고정 바이트 케이스(서버 tcp/internal/protocol/codec_test.go와 같은 값)
+const goldHeader = `{"request_id":"a1","ok":true}`
+
+// 29바이트 헤더 + 5바이트 페이로드
+const goldFrame = "\x00\x00\x00\x1d\x00\x00\x00\x05" + goldHeader + "\x00\x01\xf7\xff\n"
+
+var codecCases = []struct {
+	name    string
+	mode    string
+	max     int
+	wire    string
+	header  string
+	payload string
+	err     string
+}{
+	{name: "line message", mode: "line", max: 64, wire: goldHeader + "\n", header: goldHeader},
+	{name: "line at limit", mode: "line", max: 29, wire: goldHeader + "\n", header: goldHeader},
+	{name: "line over limit", mode: "line", max: 28, wire: goldHeader + "\n", err: "too_large"},
+	{name: "line over limit past reader buffer", mode: "line", max: 4200, wire: `{"request_id":"a1","pad":"` + strings.Repeat("x", 5000) + `"}` + "\n", err: "too_large"},
+	{name: "line truncated", mode: "line", max: 64, wire: goldHeader, err: "eof"},
+	{name: "frame header only", mode: "frame", max: 64, wire: "\x00\x00\x00\x1d\x00\x00\x00\x00" + goldHeader, header: goldHeader},
+	{name: "frame with payload", mode: "frame", max: 64, wire: goldFrame, header: goldHeader, payload: "\x00\x01\xf7\xff\n"},
+	{name: "frame at limit", mode: "frame", max: 34, wire: goldFrame, header: goldHeader, payload: "\x00\x01\xf7\xff\n"},
+	{name: "frame over limit", mode: "frame", max: 33, wire: goldFrame, err: "too_large"},
+	{name: "frame huge lengths rejected before body", mode: "frame", max: 64, wire: "\xff\xff\xff\xf0\xff\xff\xff\xf0", err: "too_large"},
+	{name: "frame payload length alone over limit", mode: "frame", max: 64, wire: "\x00\x00\x00\x02\x00\x3f\xff\xff{}", err: "too_large"},
+	{name: "frame truncated body", mode: "frame", max: 64, wire: goldFrame[:40], err: "eof"},
+}
+
+// 서버 역할: 프레임 모드면 매직 바이트를 받고 reply로 응답한 뒤 wire 전송
+func fakeServer(t *testing.T, frame bool, reply byte, wire []byte) net.Conn {
+	t.Helper()
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	go func() {
+		if frame {
+			var b [1]byte
+			if _, err := io.ReadFull(server, b[:]); err != nil || b[0] != frameMagic {
+				return
+			}
+			if _, err := server.Write([]byte{reply}); err != nil {
+				return
+			}
+		}
+		_, _ = server.Write(wire)
+		server.Close()
+	}()
+	return client
+}
+
+func TestFrameMagic(t *testing.T) {
+	// 서버와 약속한 값
+	if frameMagic != 0xf7 {
+		t.Fatalf("frameMagic = %#x, want 0xf7", frameMagic)
+	}
+}
+
+func TestClientCodecHandshake(t *testing.T) {
+	// 서버가 같은 매직 바이트로 응답하면 프레임 모드
+	conn := fakeServer(t, true, frameMagic, []byte{0, 0, 0, 2, 0, 0, 0, 0, '{', '}'})
+	c, err := clientCodec(conn, FramingFrame, 0, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.binary() {
+		t.Fatal("frame handshake did not select frame mode")
+	}
+	if m, err := c.readMessage(); err != nil || string(m.header) != "{}" {
+		t.Fatalf("first frame = %q, %v", m.header, err)
+	}
+
+	// 구버전 서버(줄 모드만)는 다른 바이트를 돌려줌
+	conn = fakeServer(t, true, '{', nil)
+	if _, err := clientCodec(conn, FramingFrame, 0, time.Second); err == nil {
+		t.Fatal("unexpected handshake reply accepted")
+	}
+
+	// 줄 모드는 매직 바이트를 보내지 않음
+	conn = fakeServer(t, false, 0, []byte("{}\n"))
+	c, err = clientCodec(conn, FramingLine, 0, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.binary() {
+		t.Fatal("line framing selected frame mode")
+	}
+	if m, err := c.readMessage(); err != nil || string(m.header) != "{}" {
+		t.Fatalf("first line = %q, %v", m.header, err)
+	}
+}
+
+func TestCodecCases(t *testing.T) {
+	for _, tc := range codecCases {
+		t.Run(tc.name, func(t *testing.T) {
+			wire := []byte(tc.wire)
+			frame := tc.mode == "frame"
+			framing := FramingLine
+			if frame {
+				framing = FramingFrame
+			}
+			conn := fakeServer(t, frame, frameMagic, wire)
+			c, err := clientCodec(conn, framing, tc.max, time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// 읽기
+			m, err := c.readMessage()
+			switch tc.err {
+			case "too_large":
+				if !errors.Is(err, errFrameTooLarge) {
+					t.Fatalf("err = %v, want %v", err, errFrameTooLarge)
+				}
+				return
+			case "eof":
+				if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+					t.Fatalf("err = %v, want EOF", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(m.header) != tc.header || !bytes.Equal(m.payload, []byte(tc.payload)) {
+				t.Fatalf("read %q %x, want %q %x", m.header, m.payload, tc.header, tc.payload)
+			}
+
+			// 쓰기는 같은 바이트를 만들어야 함
+			var out bytes.Buffer
+			wc := codec(&lineCodec{w: &out, max: tc.max})
+			if frame {
+				wc = &frameCodec{w: &out, max: tc.max}
+			}
+			if err := wc.writeMessage(message{header: m.header, payload: m.payload}); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(out.Bytes(), wire) {
+				t.Fatalf("write = %x, want %x", out.Bytes(), wire)
+			}
+		})
+	}
+}
+
+func TestCodecWriteLimits(t *testing.T) {
+	var out bytes.Buffer
+
+	// 쓰기도 같은 상한 적용
+	if err := (&frameCodec{w: &out, max: 4}).writeMessage(message{header: []byte("{}"), payload: []byte("abc")}); !errors.Is(err, errFrameTooLarge) {
+		t.Fatalf("frame write err = %v", err)
+	}
+	if err := (&lineCodec{w: &out, max: 1}).writeMessage(message{header: []byte("{}")}); !errors.Is(err, errFrameTooLarge) {
+		t.Fatalf("line write err = %v", err)
+	}
+	// 줄 모드는 바이너리 불가
+	if err := (&lineCodec{w: &out, max: 64}).writeMessage(message{header: []byte("{}"), payload: []byte{1}}); err == nil {
+		t.Fatal("line payload accepted")
+	}
+	if out.Len() != 0 {
+		t.Fatalf("rejected writes wrote %x", out.Bytes())
+	}
+}
+
+// 선언된 길이가 커도 본문을 읽거나 할당하기 전에 거절
+func TestFrameSizeCheckedBeforeAlloc(t *testing.T) {
+	hdr := []byte{0xff, 0xff, 0xff, 0xf0, 0xff, 0xff, 0xff, 0xf0}
+	allocs := testing.AllocsPerRun(10, func() {
+		c := &frameCodec{r: bytes.NewReader(hdr), max: defaultMaxFrame}
+		if _, err := c.readMessage(); !errors.Is(err, errFrameTooLarge) {
+			t.Fatalf("err = %v", err)
+		}
+	})
+	// 코덱/리더 구조체 외 본문 버퍼 할당 없음
+	if allocs > 3 {
+		t.Fatalf("allocs = %v", allocs)
+	}
+}
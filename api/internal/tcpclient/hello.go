@@ -0,0 +1,78 @@
+package tcpclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// 클라이언트 프로토콜 버전
+const protocolVersion = 1
+
+// 클라이언트가 쓰는 요청 타입
+var clientCaps = []string{"ping", "cmd", "file", "list", "stat", "hash", "write", "cancel", "policy"}
+
+// 서버와 버전/기능이 맞지 않음
+var ErrIncompatible = errors.New("tcp protocol incompatible")
+
+// hello로 받은 서버 정보
+type serverInfo struct {
+	// 지원 요청 타입
+	caps map[string]bool
+	// 연결당 동시 처리 요청 수(0이면 알리지 않는 구버전 서버)
+	maxInFlight int
+	// 실행 슬롯 최대 대기 시간(0이면 알리지 않는 구버전 서버)
+	queueTimeout time.Duration
+}
+
+// 연결 직후 버전/기능 협상(수신 루프 시작 전 동기 처리)
+func handshake(conn net.Conn, c codec, timeout time.Duration) (serverInfo, error) {
+	// 협상 응답 대기 제한
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+	defer conn.SetDeadline(time.Time{})
+
+	// hello 전송
+	b, err := json.Marshal(Req{
+		RequestID: "hello",
+		Type:      "hello",
+		Version:   protocolVersion,
+		Caps:      clientCaps,
+	})
+	if err != nil {
+		return serverInfo{}, err
+	}
+	if err := c.writeMessage(message{header: b}); err != nil {
+		return serverInfo{}, err
+	}
+
+	// 응답 수신
+	msg, err := c.readMessage()
+	if err != nil {
+		return serverInfo{}, fmt.Errorf("%w: no hello reply: %v", ErrIncompatible, err)
+	}
+	var res Res
+	if err := json.Unmarshal(msg.header, &res); err != nil {
+		return serverInfo{}, fmt.Errorf("%w: bad hello reply: %v", ErrIncompatible, err)
+	}
+
+	// 거절/버전 불일치
+	if !res.Ok {
+		return serverInfo{}, fmt.Errorf("%w: %s", ErrIncompatible, res.Error)
+	}
+	if res.Version != protocolVersion {
+		return serverInfo{}, fmt.Errorf("%w: server speaks version %d, client %d", ErrIncompatible, res.Version, protocolVersion)
+	}
+
+	// 서버 지원 타입
+	caps := make(map[string]bool, len(res.Caps))
+	for _, t := range res.Caps {
+		caps[t] = true
+	}
+	return serverInfo{
+		caps:         caps,
+		maxInFlight:  res.MaxInFlight,
+		queueTimeout: time.Duration(res.QueueTimeoutMs) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,516 @@
+package tcpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// 풀 기본값
+const (
+	defaultMaxConns       = 4
+	defaultIdleTimeout    = 60 * time.Second
+	defaultHealthInterval = 15 * time.Second
+	// 요청별 수신 버퍼(스트림 이벤트)
+	waiterBuffer = 64
+	// hello로 알려주지 않는 구버전 서버의 연결당 동시 처리 수
+	defaultMaxInFlight = 32
+	// 취소 후 서버의 최종 결과 대기
+	cancelGrace = 2 * time.Second
+)
+
+// 연결/풀 상태 에러
+var (
+	errPoolClosed  = errors.New("tcp client closed")
+	errConnClosed  = errors.New("tcp connection closed")
+	errDuplicateID = errors.New("duplicate request_id in flight")
+	errOverflow    = errors.New("response buffer overflow (slow consumer)")
+	errPoolBusy    = errors.New("all tcp connections are busy")
+)
+
+// 응답 대기자(request_id 단위)
+type waiter struct {
+	// 수신 메시지
+	ch chan message
+	// 수신 버퍼 초과 신호(이후 메시지는 버림)
+	overflow chan struct{}
+}
+
+// 버퍼 초과로 대기자 실패 처리(readLoop에서만 호출)
+func (w *waiter) fail() {
+	select {
+	case <-w.overflow:
+	default:
+		close(w.overflow)
+	}
+}
+
+// 다중화 연결 한 개
+type muxConn struct {
+	conn net.Conn
+	// 줄/프레임 직렬화
+	codec codec
+	// 서버 지원 요청 타입
+	caps map[string]bool
+	// 연결당 동시 요청 상한(서버 hello 값)
+	limit int
+	// 서버 실행 슬롯 대기 상한(서버 hello 값, 응답 기한에 더함)
+	queueTimeout time.Duration
+	// 스트림 전용 연결: 버퍼가 차면 수신을 멈춰 서버 쓰기에 역압
+	exclusive bool
+	// 쓰기 직렬화
+	wmu sync.Mutex
+
+	// request_id → 대기자
+	mu       sync.Mutex
+	pending  map[string]*waiter
+	lastUsed time.Time
+
+	// 종료 신호 + 원인
+	done      chan struct{}
+	err       error
+	closeOnce sync.Once
+}
+
+// 연결 생성 + 수신 루프 시작(exclusive면 스트림 전용)
+func dialMux(ctx context.Context, cfg Config, tc *tls.Config, exclusive bool) (*muxConn, error) {
+	// TCP 연결 (Context + 연결 타임아웃 적용)
+	dialer := &net.Dialer{Timeout: cfg.DialTimeout, KeepAlive: 30 * time.Second}
+	addr := net.JoinHostPort(cfg.Host, cfg.Port)
+
+	var conn net.Conn
+	var err error
+	if tc != nil {
+		// TLS 핸드셰이크까지 연결 타임아웃 적용
+		td := &tls.Dialer{NetDialer: dialer, Config: tc}
+		conn, err = td.DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 전송 방식 협상
+	c, err := clientCodec(conn, cfg.Framing, cfg.MaxFrame, cfg.DialTimeout)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	// 버전/기능 협상
+	info, err := handshake(conn, c, cfg.DialTimeout)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	limit := info.maxInFlight
+	if limit <= 0 {
+		limit = defaultMaxInFlight
+	}
+	m := &muxConn{
+		conn:         conn,
+		codec:        c,
+		caps:         info.caps,
+		limit:        limit,
+		queueTimeout: info.queueTimeout,
+		exclusive:    exclusive,
+		pending:      make(map[string]*waiter),
+		lastUsed:     time.Now(),
+		done:         make(chan struct{}),
+	}
+	go m.readLoop()
+	return m, nil
+}
+
+// 응답을 request_id로 분배
+func (m *muxConn) readLoop() {
+	for {
+		// 메시지 한 건 수신
+		msg, err := m.codec.readMessage()
+		if err != nil {
+			m.close(err)
+			return
+		}
+
+		// 라우팅용 ID만 파싱
+		var hdr struct {
+			RequestID string `json:"request_id"`
+		}
+		if err := json.Unmarshal(msg.header, &hdr); err != nil {
+			continue
+		}
+
+		// 대기자 조회(이미 떠났으면 버림)
+		m.mu.Lock()
+		w := m.pending[hdr.RequestID]
+		m.mu.Unlock()
+		if w == nil {
+			continue
+		}
+
+		// 전용 연결은 소비자를 기다림(TCP 흐름 제어로 서버 출력도 대기)
+		if m.exclusive {
+			select {
+			case w.ch <- msg:
+			case <-m.done:
+				return
+			}
+			continue
+		}
+
+		// 느린 대기자 때문에 수신 루프(다른 요청)가 막히지 않게 버퍼가 차면 해당 요청만 실패
+		select {
+		case <-w.overflow:
+		case w.ch <- msg:
+		default:
+			w.fail()
+		}
+	}
+}
+
+// 서버 지원 여부(타입 생략은 cmd)
+func (m *muxConn) supports(reqType string) bool {
+	if reqType == "" {
+		reqType = "cmd"
+	}
+	return m.caps[reqType]
+}
+
+// 대기자 등록
+func (m *muxConn) register(id string) (*waiter, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed() {
+		return nil, errConnClosed
+	}
+	if _, dup := m.pending[id]; dup {
+		return nil, errDuplicateID
+	}
+	// 서버 상한을 넘기면 서버가 QUEUE_FULL로 거절하므로 미리 다른 연결로
+	if len(m.pending) >= m.limit {
+		return nil, errPoolBusy
+	}
+	w := &waiter{ch: make(chan message, waiterBuffer), overflow: make(chan struct{})}
+	m.pending[id] = w
+	return w, nil
+}
+
+// 대기자 해제
+func (m *muxConn) unregister(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.pending, id)
+}
+
+// 사용 시각 갱신(헬스체크 ping은 제외)
+func (m *muxConn) touch() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastUsed = time.Now()
+}
+
+// 요청 한 건 전송(JSON은 서명까지 끝난 상태)
+func (m *muxConn) send(b, payload []byte, timeout time.Duration) error {
+	m.wmu.Lock()
+	defer m.wmu.Unlock()
+
+	// 쓰기 타임아웃
+	_ = m.conn.SetWriteDeadline(time.Now().Add(timeout))
+	return m.codec.writeMessage(message{header: b, payload: payload})
+}
+
+// 처리중 요청 수
+func (m *muxConn) load() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.pending)
+}
+
+// 마지막 사용 이후 경과
+func (m *muxConn) idleFor() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.pending) > 0 {
+		return 0
+	}
+	return time.Since(m.lastUsed)
+}
+
+// 종료 여부
+func (m *muxConn) closed() bool {
+	select {
+	case <-m.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// 연결 종료(대기자는 done으로 깨어남)
+func (m *muxConn) close(err error) {
+	m.closeOnce.Do(func() {
+		if err == nil {
+			err = errConnClosed
+		}
+		m.err = err
+		close(m.done)
+		_ = m.conn.Close()
+	})
+}
+
+// 연결 풀
+type pool struct {
+	cfg Config
+	// TLS 설정(nil이면 평문)
+	tls *tls.Config
+	// 요청 서명기(nil이면 서명 안 함)
+	sign *signer
+
+	mu     sync.Mutex
+	conns  []*muxConn
+	closed bool
+	// 스트림 전용 연결(풀 종료 시 함께 닫음)
+	streams map[*muxConn]struct{}
+
+	// 백그라운드 정리 종료
+	stop chan struct{}
+}
+
+// 풀 생성 + 정리 루프 시작
+func newPool(cfg Config, tc *tls.Config, sg *signer) *pool {
+	p := &pool{cfg: cfg, tls: tc, sign: sg, streams: make(map[*muxConn]struct{}), stop: make(chan struct{})}
+	go p.janitor()
+	return p
+}
+
+// 요청을 보낼 연결 선택
+// - 가장 한가한 연결을 쓰되, 바쁘고 여유 슬롯이 있으면 새로 연결
+// - 모든 연결이 서버 상한만큼 바쁘고 슬롯도 없으면 errPoolBusy
+func (p *pool) get(ctx context.Context) (*muxConn, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, errPoolClosed
+	}
+
+	// 끊긴 연결 제거 + 상한 미만 중 최소 부하 선택
+	best, bestLoad := p.leastLoaded()
+
+	// 한가하거나 슬롯이 없으면 기존 연결 사용
+	full := len(p.conns) >= p.cfg.MaxConns
+	if best != nil && (bestLoad == 0 || full) {
+		p.mu.Unlock()
+		return best, nil
+	}
+	p.mu.Unlock()
+	if best == nil && full {
+		return nil, errPoolBusy
+	}
+
+	// 새 연결(잠금 밖에서 dial)
+	m, err := dialMux(ctx, p.cfg, p.tls, false)
+	if err != nil {
+		// 기존 연결이라도 있으면 사용
+		if best != nil {
+			return best, nil
+		}
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		m.close(errPoolClosed)
+		return nil, errPoolClosed
+	}
+	// 동시에 dial되어 넘치면 새 연결은 닫고 기존 사용
+	if existing, _ := p.leastLoaded(); existing != nil && len(p.conns) >= p.cfg.MaxConns {
+		m.close(nil)
+		return existing, nil
+	}
+	p.conns = append(p.conns, m)
+	return m, nil
+}
+
+// 끊긴 연결 제거 후 상한 미만 중 최소 부하 연결(p.mu 보유 상태)
+func (p *pool) leastLoaded() (*muxConn, int) {
+	var best *muxConn
+	bestLoad := 0
+	alive := p.conns[:0]
+	for _, m := range p.conns {
+		if m.closed() {
+			continue
+		}
+		alive = append(alive, m)
+		l := m.load()
+		if l >= m.limit {
+			continue
+		}
+		if best == nil || l < bestLoad {
+			best, bestLoad = m, l
+		}
+	}
+	p.conns = alive
+	return best, bestLoad
+}
+
+// 스트림 전용 연결(다른 요청과 공유하지 않아 느린 소비자가 연결을 멈춰도 됨)
+func (p *pool) dedicated(ctx context.Context) (*muxConn, error) {
+	p.mu.Lock()
+	closed := p.closed
+	p.mu.Unlock()
+	if closed {
+		return nil, errPoolClosed
+	}
+
+	m, err := dialMux(ctx, p.cfg, p.tls, true)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		m.close(errPoolClosed)
+		return nil, errPoolClosed
+	}
+	p.streams[m] = struct{}{}
+	return m, nil
+}
+
+// 스트림 전용 연결 종료 + 목록에서 제거
+func (p *pool) drop(m *muxConn) {
+	p.mu.Lock()
+	delete(p.streams, m)
+	p.mu.Unlock()
+	m.close(nil)
+}
+
+// 유휴 연결 정리 + 헬스체크
+func (p *pool) janitor() {
+	t := time.NewTicker(p.cfg.HealthInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-t.C:
+		}
+
+		// 현재 연결 스냅샷
+		p.mu.Lock()
+		conns := append([]*muxConn(nil), p.conns...)
+		p.mu.Unlock()
+
+		for _, m := range conns {
+			idle := m.idleFor()
+			switch {
+			case m.closed():
+				// get에서 제거됨
+			case idle > p.cfg.IdleTimeout:
+				// 오래 쉰 연결 정리
+				m.close(nil)
+			case idle > 0:
+				// 쉬는 연결만 ping
+				if err := p.ping(m); err != nil {
+					m.close(err)
+				}
+			}
+		}
+
+		// 닫힌 연결 제거
+		p.mu.Lock()
+		p.leastLoaded()
+		p.mu.Unlock()
+	}
+}
+
+// 연결 상태 확인
+func (p *pool) ping(m *muxConn) error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.cfg.IOTimeout)
+	defer cancel()
+
+	req := Req{RequestID: "ping-" + newID(), Type: "ping"}
+	w, err := m.register(req.RequestID)
+	if err != nil {
+		return err
+	}
+	defer m.unregister(req.RequestID)
+
+	b, err := p.sign.encode(req, nil)
+	if err != nil {
+		return err
+	}
+	if err := m.send(b, nil, p.cfg.IOTimeout); err != nil {
+		return err
+	}
+
+	msg, err := m.recv(ctx, w)
+	if err != nil {
+		return err
+	}
+	res, err := decodeRes(msg)
+	if err != nil {
+		return err
+	}
+	if !res.Ok {
+		return errors.New("ping failed: " + res.Error)
+	}
+	return nil
+}
+
+// 대기자 메시지 한 건 수신
+func (m *muxConn) recv(ctx context.Context, w *waiter) (message, error) {
+	// 일부 메시지가 유실되었으므로 남은 버퍼도 버림
+	select {
+	case <-w.overflow:
+		return message{}, errOverflow
+	default:
+	}
+
+	select {
+	case <-w.overflow:
+		return message{}, errOverflow
+	case msg := <-w.ch:
+		return msg, nil
+	case <-m.done:
+		// 연결이 닫혀도 먼저 도착한 메시지는 전달
+		select {
+		case msg := <-w.ch:
+			return msg, nil
+		default:
+		}
+		return message{}, m.err
+	case <-ctx.Done():
+		return message{}, ctx.Err()
+	}
+}
+
+// 전체 연결 종료
+func (p *pool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return
+	}
+	p.closed = true
+	close(p.stop)
+	for _, m := range p.conns {
+		m.close(errPoolClosed)
+	}
+	p.conns = nil
+	for m := range p.streams {
+		m.close(errPoolClosed)
+	}
+	p.streams = nil
+}
@@ -0,0 +1,146 @@
+package tcpclient
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+// 파이프 위 다중화 연결(hello 생략)
+func testMux(conn net.Conn, exclusive bool) *muxConn {
+	return &muxConn{
+		conn:      conn,
+		codec:     &lineCodec{r: bufio.NewReader(conn), w: conn, max: defaultMaxFrame},
+		limit:     defaultMaxInFlight,
+		exclusive: exclusive,
+		pending:   make(map[string]*waiter),
+		done:      make(chan struct{}),
+	}
+}
+
+// 느린 대기자가 버퍼를 넘겨도 수신 루프는 다른 요청을 계속 분배
+func TestReadLoopSlowWaiter(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	m := testMux(client, false)
+	defer m.close(nil)
+	go m.readLoop()
+
+	slow, err := m.register("slow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast, err := m.register("fast")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// slow 버퍼를 넘치게 보낸 뒤 fast 응답 한 건
+	go func() {
+		for i := 0; i <= waiterBuffer; i++ {
+			fmt.Fprintf(server, "{\"request_id\":\"slow\",\"seq\":%d}\n", i)
+		}
+		fmt.Fprintf(server, "{\"request_id\":\"fast\"}\n")
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if _, err := m.recv(ctx, fast); err != nil {
+		t.Fatalf("fast recv: %v", err)
+	}
+	if _, err := m.recv(ctx, slow); !errors.Is(err, errOverflow) {
+		t.Fatalf("slow recv err = %v, want %v", err, errOverflow)
+	}
+	if got := errCode(errOverflow); got != CodeBackendUnavailable {
+		t.Fatalf("errCode(errOverflow) = %q", got)
+	}
+}
+
+// 스트림 전용 연결은 버퍼가 차도 실패하지 않고 서버 쓰기를 멈춤
+func TestReadLoopExclusiveBackpressure(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	m := testMux(client, true)
+	defer m.close(nil)
+	go m.readLoop()
+
+	w, err := m.register("s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 버퍼보다 많이 전송(읽지 않는 동안 서버 쓰기가 막힘)
+	total := waiterBuffer + 8
+	sent := make(chan int, 1)
+	go func() {
+		n := 0
+		_ = server.SetWriteDeadline(time.Now().Add(200 * time.Millisecond))
+		for ; n < total; n++ {
+			if _, err := fmt.Fprintf(server, "{\"request_id\":\"s\",\"seq\":%d}\n", n); err != nil {
+				break
+			}
+		}
+		sent <- n
+	}()
+	if n := <-sent; n >= total {
+		t.Fatalf("server wrote all %d messages without a reader", n)
+	}
+
+	// 소비자가 따라오면 남은 메시지도 순서대로 도착
+	go func() {
+		_ = server.SetWriteDeadline(time.Time{})
+		for n := 0; n < total; n++ {
+			fmt.Fprintf(server, "{\"request_id\":\"s\",\"seq\":%d}\n", n)
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	for i := 0; i < waiterBuffer+1; i++ {
+		if _, err := m.recv(ctx, w); err != nil {
+			t.Fatalf("recv %d: %v", i, err)
+		}
+	}
+}
+
+// 서버 상한만큼 바쁜 연결에는 더 보내지 않음
+func TestPoolInFlightLimit(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	m := testMux(client, false)
+	m.limit = 2
+	defer m.close(nil)
+
+	p := &pool{cfg: Config{MaxConns: 1}, conns: []*muxConn{m}}
+	for _, id := range []string{"a", "b"} {
+		got, err := p.get(context.Background())
+		if err != nil || got != m {
+			t.Fatalf("get before limit: %v", err)
+		}
+		if _, err := m.register(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := m.register("c"); !errors.Is(err, errPoolBusy) {
+		t.Fatalf("register over limit err = %v", err)
+	}
+	if _, err := p.get(context.Background()); !errors.Is(err, errPoolBusy) {
+		t.Fatalf("get over limit err = %v", err)
+	}
+	if got := errCode(errPoolBusy); got != CodeQueueFull {
+		t.Fatalf("errCode(errPoolBusy) = %q", got)
+	}
+
+	// 하나 끝나면 다시 사용
+	m.unregister("a")
+	if got, err := p.get(context.Background()); err != nil || got != m {
+		t.Fatalf("get after release: %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package tcpclient
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// 요청 서명기(TCP 서버와 공유한 비밀키)
+type signer struct {
+	keyID string
+	key   []byte
+}
+
+// 요청 JSON 생성(서명기가 있으면 ts/nonce/sig 포함)
+func (s *signer) encode(req Req, payload []byte) ([]byte, error) {
+	if s == nil {
+		return json.Marshal(req)
+	}
+
+	// 서명 필드 채우기
+	req.KeyID = s.keyID
+	req.Ts = time.Now().UnixMilli()
+	req.Nonce = newNonce()
+	req.Sig = ""
+
+	// sig 없는 JSON으로 서명
+	b, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := sign(s.key, b, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Sig = hex.EncodeToString(sig)
+	return json.Marshal(req)
+}
+
+// HMAC-SHA256(key, canonical(header) || payload)
+func sign(key, header, payload []byte) ([]byte, error) {
+	c, err := canonical(header)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(c)
+	mac.Write(payload)
+	return mac.Sum(nil), nil
+}
+
+// 서명 대상 JSON: sig를 뺀 뒤 키 정렬 + 공백 제거(서버와 동일 규칙)
+func canonical(header []byte) ([]byte, error) {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(header, &m); err != nil {
+		return nil, err
+	}
+	delete(m, "sig")
+	return json.Marshal(m)
+}
+
+// 재전송 방지용 nonce
+func newNonce() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,152 @@
+package tcpclient
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// 알려진 답 벡터(서버 tcp/internal/auth/hmac_test.go가 같은 값을 검증)
+const vectorKeyID, vectorKey, vectorTs = "k1", "test-secret", 1792220955937
+
+var hmacVectors = []struct {
+	name      string
+	req       Req
+	header    string
+	payload   string
+	canonical string
+}{
+	{
+		name:      "cmd",
+		req:       Req{RequestID: "r1", UserID: "alice", Type: "cmd", Cmd: "ls -la logs", TimeoutMs: 1500, Nonce: "cf460b6f3cfb5304fc569299542af488"},
+		header:    `{"request_id":"r1","user_id":"alice","type":"cmd","cmd":"ls -la logs","timeout_ms":1500,"key_id":"k1","ts":1792220955937,"nonce":"cf460b6f3cfb5304fc569299542af488","sig":"9db697c0f9d8a5bef8fd83f371f1b8d9a51dcb0ea9111724958d28596b74bec3"}`,
+		payload:   "",
+		canonical: `{"cmd":"ls -la logs","key_id":"k1","nonce":"cf460b6f3cfb5304fc569299542af488","request_id":"r1","timeout_ms":1500,"ts":1792220955937,"type":"cmd","user_id":"alice"}`,
+	},
+	{
+		name:      "unicode and escapes",
+		req:       Req{RequestID: "r2", UserID: "김철수", Type: "list", Path: "data:a/<b>&\"c\"", Pattern: "*.log", Nonce: "43b39c9774fa4568b658399dd9fc9b4a"},
+		header:    `{"request_id":"r2","user_id":"김철수","type":"list","path":"data:a/\u003cb\u003e\u0026\"c\"","pattern":"*.log","key_id":"k1","ts":1792220955937,"nonce":"43b39c9774fa4568b658399dd9fc9b4a","sig":"e8764217e96ec0a8ee50970ea54a68a2f658ed65db6ad3aea8f6527d584f8d2e"}`,
+		payload:   "",
+		canonical: `{"key_id":"k1","nonce":"43b39c9774fa4568b658399dd9fc9b4a","path":"data:a/\u003cb\u003e\u0026\"c\"","pattern":"*.log","request_id":"r2","ts":1792220955937,"type":"list","user_id":"김철수"}`,
+	},
+	{
+		name:      "write with payload",
+		req:       Req{RequestID: "r3", UserID: "ci", Type: "write", Op: "append", UploadID: "u1", Offset: 4096, Nonce: "93c5a107dbe5f5ee8576b1492de96453"},
+		header:    `{"request_id":"r3","user_id":"ci","type":"write","offset":4096,"op":"append","upload_id":"u1","key_id":"k1","ts":1792220955937,"nonce":"93c5a107dbe5f5ee8576b1492de96453","sig":"338a584afed2bb32562e10cd908907aa38f121cee720c5e0dce33a5cf16b4c44"}`,
+		payload:   "\x00\xf7\xff\nx",
+		canonical: `{"key_id":"k1","nonce":"93c5a107dbe5f5ee8576b1492de96453","offset":4096,"op":"append","request_id":"r3","ts":1792220955937,"type":"write","upload_id":"u1","user_id":"ci"}`,
+	},
+}
+
+func TestSignKnownAnswers(t *testing.T) {
+	for _, tc := range hmacVectors {
+		t.Run(tc.name, func(t *testing.T) {
+			// encode와 같은 순서: sig 없이 직렬화 → 서명 → sig 추가
+			req := tc.req
+			req.KeyID, req.Ts = vectorKeyID, vectorTs
+			b, err := json.Marshal(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// 서버와 같은 정규화 결과
+			c, err := canonical(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(c) != tc.canonical {
+				t.Fatalf("canonical = %s, want %s", c, tc.canonical)
+			}
+
+			// 같은 서명 + 같은 헤더 바이트
+			sig, err := sign([]byte(vectorKey), b, []byte(tc.payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Sig = hex.EncodeToString(sig)
+			if b, err = json.Marshal(req); err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tc.header {
+				t.Fatalf("header = %s, want %s", b, tc.header)
+			}
+		})
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	// 키 순서/공백/sig 유무와 무관하게 같은 바이트
+	a, err := canonical([]byte(`{"ts":1,"cmd":"id","sig":"ab","user_id":"u"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := canonical([]byte(" {\n \"user_id\" : \"u\", \"cmd\":\"id\" ,\"ts\": 1 }"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a, b) || string(a) != `{"cmd":"id","ts":1,"user_id":"u"}` {
+		t.Fatalf("canonical = %s / %s", a, b)
+	}
+	if _, err := canonical([]byte("not json")); err == nil {
+		t.Fatal("invalid json accepted")
+	}
+}
+
+func TestSignerEncode(t *testing.T) {
+	s := &signer{keyID: "k1", key: []byte("test-secret")}
+	req := Req{RequestID: "r1", UserID: "alice", Type: "cmd", Cmd: "id", Sig: "stale"}
+
+	before := time.Now().UnixMilli()
+	b1, err := s.encode(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b2, err := s.encode(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r1, r2 Req
+	if err := json.Unmarshal(b1, &r1); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b2, &r2); err != nil {
+		t.Fatal(err)
+	}
+
+	// 서명 필드 채움(기존 sig는 무시)
+	if r1.KeyID != "k1" || r1.Ts < before || r1.Ts > time.Now().UnixMilli() {
+		t.Fatalf("key_id/ts = %q/%d", r1.KeyID, r1.Ts)
+	}
+	if len(r1.Nonce) != 32 || r1.Nonce == r2.Nonce {
+		t.Fatalf("nonces = %q, %q", r1.Nonce, r2.Nonce)
+	}
+	sig, err := sign(s.key, b1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(sig) != r1.Sig {
+		t.Fatalf("sig does not cover the sent header")
+	}
+
+	// payload도 서명 대상
+	withPayload, err := sign(s.key, b1, []byte("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(withPayload, sig) {
+		t.Fatal("payload not signed")
+	}
+
+	// 서명기 없으면 서명 필드 없음
+	plain, err := (*signer)(nil).encode(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(plain, []byte(`"nonce"`)) || bytes.Contains(plain, []byte(`"key_id"`)) {
+		t.Fatalf("unsigned request = %s", plain)
+	}
+}
@@ -1,10 +1,15 @@
 package tcpclient
 
 import (
-	"bufio"
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
-	"net"
+	"errors"
+	"fmt"
+	"hash/crc32"
 	"time"
 )
 
@@ -14,26 +19,73 @@ type Req struct {
 	RequestID string `json:"request_id,omitempty" yaml:"request_id,omitempty" form:"request_id"`
 	// 사용자 ID
 	UserID string `json:"user_id,omitempty" yaml:"user_id,omitempty" form:"user_id"`
-	// 작업 타입(cmd/file)
+	// 작업 타입(hello/ping/cmd/file/list/stat/hash/write/cancel/policy)
 	Type string `json:"type,omitempty" yaml:"type,omitempty" form:"type"`
 
+	// hello: 프로토콜 버전
+	Version int `json:"version,omitempty" yaml:"-" form:"-"`
+	// hello: 사용할 요청 타입
+	Caps []string `json:"caps,omitempty" yaml:"-" form:"-"`
+
 	// cmd 실행
 	Cmd string `json:"cmd,omitempty" yaml:"cmd,omitempty" form:"cmd"`
+	// 실행 제한시간(ms, 0이면 서버 기본값)
+	TimeoutMs int64 `json:"timeout_ms,omitempty" yaml:"timeout_ms,omitempty" form:"timeout_ms"`
+	// 출력 스트리밍 여부(Stream 전용)
+	Stream bool `json:"stream,omitempty" yaml:"-" form:"-"`
 
-	// 파일 읽기
+	// 파일 읽기(list는 건너뛸 항목 수/최대 항목 수)
 	Path   string `json:"path,omitempty" yaml:"path,omitempty" form:"path"`
 	Offset int64  `json:"offset,omitempty" yaml:"offset,omitempty" form:"offset"`
 	Limit  int64  `json:"limit,omitempty" yaml:"limit,omitempty" form:"limit"`
+	// list: 이름 glob 필터
+	Pattern string `json:"pattern,omitempty" yaml:"pattern,omitempty" form:"pattern"`
+
+	// write: 단계(open/append/commit/abort)
+	Op string `json:"op,omitempty" yaml:"-" form:"-"`
+	// write: open 응답의 업로드 ID
+	UploadID string `json:"upload_id,omitempty" yaml:"-" form:"-"`
+	// write: commit 시 검증할 SHA-256(hex)
+	SHA256 string `json:"sha256,omitempty" yaml:"-" form:"-"`
+	// write: 청크(줄 모드 Base64, 전송 시 채움)
+	DataB64 string `json:"data_b64,omitempty" yaml:"-" form:"-"`
+	// write: 청크 원본(프레임 모드는 payload)
+	Data []byte `json:"-" yaml:"-" form:"-"`
+
+	// 요청 서명(클라이언트가 채움)
+	KeyID string `json:"key_id,omitempty" yaml:"-" form:"-"`
+	Ts    int64  `json:"ts,omitempty" yaml:"-" form:"-"`
+	Nonce string `json:"nonce,omitempty" yaml:"-" form:"-"`
+	Sig   string `json:"sig,omitempty" yaml:"-" form:"-"`
 }
 
 // TCP 응답 스키마
 type Res struct {
 	// 성공 여부
 	Ok bool `json:"ok,omitempty" yaml:"ok,omitempty"`
-	// 출력(텍스트)
+	// 출력(stdout+stderr, 하위 호환)
 	Output string `json:"output,omitempty" yaml:"output,omitempty"`
+	// 표준 출력
+	Stdout string `json:"stdout,omitempty" yaml:"stdout,omitempty"`
+	// 표준 에러
+	Stderr string `json:"stderr,omitempty" yaml:"stderr,omitempty"`
 	// 에러 메시지
 	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+	// 실패 사유(exit/timeout/killed)
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+	// 에러 코드(기계 판별용)
+	Code string `json:"code,omitempty" yaml:"code,omitempty"`
+	// 출력 잘림 여부
+	Truncated bool `json:"truncated,omitempty" yaml:"truncated,omitempty"`
+	// 종료 코드(nil이면 cmd 응답 아님)
+	ExitCode *int `json:"exit_code,omitempty" yaml:"exit_code,omitempty"`
+	// 실행 시작 시각
+	StartedAt time.Time `json:"started_at,omitzero" yaml:"started_at,omitempty"`
+	// 실행 시간(ms)
+	DurationMs int64 `json:"duration_ms,omitempty" yaml:"duration_ms,omitempty"`
+	// TCP 서버 실행 대기열: 앞의 대기 수 / 기다린 시간(ms)
+	QueueDepth  int   `json:"queue_depth,omitempty" yaml:"queue_depth,omitempty"`
+	QueueWaitMs int64 `json:"queue_wait_ms,omitempty" yaml:"queue_wait_ms,omitempty"`
 
 	// 추적용 ID
 	RequestID string `json:"request_id,omitempty" yaml:"request_id,omitempty"`
@@ -44,12 +96,132 @@ type Res struct {
 	TcpLocal  string `json:"tcp_local,omitempty" yaml:"tcp_local,omitempty"`
 	TcpRemote string `json:"tcp_remote,omitempty" yaml:"tcp_remote,omitempty"`
 
-	// 파일 청크(Base64)
+	// hello: 합의된 버전
+	Version int `json:"version,omitempty" yaml:"-"`
+	// hello: 서버 지원 요청 타입
+	Caps []string `json:"caps,omitempty" yaml:"-"`
+	// hello: 연결당 동시 처리 요청 수
+	MaxInFlight int `json:"max_in_flight,omitempty" yaml:"-"`
+	// hello: 서버 실행 슬롯 최대 대기 시간
+	QueueTimeoutMs int64 `json:"queue_timeout_ms,omitempty" yaml:"-"`
+
+	// 파일 청크(Base64, 줄 모드)
 	FileB64 string `json:"file_b64,omitempty" yaml:"file_b64,omitempty"`
+	// 파일 청크 원본(프레임 payload 또는 file_b64 디코딩)
+	Data []byte `json:"-" yaml:"-"`
 	// 다음 오프셋
 	NextOffset int64 `json:"next_offset,omitempty" yaml:"next_offset,omitempty"`
-	// EOF 여부
+	// EOF 여부(파일 크기 기준)
 	EOF bool `json:"eof,omitempty" yaml:"eof,omitempty"`
+	// file/hash: 파일 크기
+	FileSize int64 `json:"file_size,omitempty" yaml:"file_size,omitempty"`
+	// file: 청크의, hash: 파일 전체의 체크섬(hex)
+	CRC32C string `json:"crc32c,omitempty" yaml:"crc32c,omitempty"`
+	SHA256 string `json:"sha256,omitempty" yaml:"sha256,omitempty"`
+
+	// list: 디렉터리 항목
+	Entries []FileEntry `json:"entries,omitempty" yaml:"entries,omitempty"`
+	// stat: 파일 정보(write commit은 저장된 파일)
+	Entry *FileEntry `json:"entry,omitempty" yaml:"entry,omitempty"`
+
+	// write: 업로드 ID(open 응답)
+	UploadID string `json:"upload_id,omitempty" yaml:"-"`
+	// write: commit으로 새 파일이 생김
+	Created bool `json:"created,omitempty" yaml:"-"`
+
+	// policy: 적용중인 명령 정책
+	Policy *PolicyInfo `json:"policy,omitempty" yaml:"policy,omitempty"`
+}
+
+// list/stat 항목
+type FileEntry struct {
+	// 파일 이름
+	Name string `json:"name" yaml:"name"`
+	// /file 등에 다시 쓸 수 있는 경로
+	Path string `json:"path" yaml:"path"`
+	// 종류(file/dir/symlink/other)
+	Type string `json:"type" yaml:"type"`
+	// 크기(byte)
+	Size int64 `json:"size" yaml:"size"`
+	// 권한("0644")
+	Mode string `json:"mode" yaml:"mode"`
+	// 수정 시각
+	ModTime time.Time `json:"mtime" yaml:"mtime"`
+}
+
+// TCP 서버 명령 정책
+type PolicyInfo struct {
+	// 정책 내용 해시("sha256:...")
+	Version string `json:"version" yaml:"version"`
+	// 정책 출처(파일 경로 또는 builtin)
+	Source string `json:"source" yaml:"source"`
+	// 적용 시각
+	LoadedAt time.Time `json:"loaded_at" yaml:"loaded_at"`
+	// 명령 → 인자 정책
+	Commands map[string]CommandPolicy `json:"commands" yaml:"commands"`
+}
+
+// 명령 하나의 인자 정책
+type CommandPolicy struct {
+	Flags      []string `json:"flags,omitempty" yaml:"flags,omitempty"`
+	MaxArgs    int      `json:"max_args" yaml:"max_args"`
+	ArgPattern string   `json:"arg_pattern,omitempty" yaml:"arg_pattern,omitempty"`
+	PathArgs   bool     `json:"path_args,omitempty" yaml:"path_args,omitempty"`
+}
+
+// 에러 코드(TCP 서버 protocol 코드 + 클라이언트 쪽 코드)
+const (
+	CodeBadRequest         = "BAD_REQUEST"
+	CodeUnsupportedType    = "UNSUPPORTED_TYPE"
+	CodeUnsupportedVersion = "UNSUPPORTED_VERSION"
+	CodeProtocolError      = "PROTOCOL_ERROR"
+	CodeDuplicateRequestID = "DUPLICATE_REQUEST_ID"
+	CodeFrameTooLarge      = "FRAME_TOO_LARGE"
+	CodeUnauthorized       = "UNAUTHORIZED"
+	CodeForbidden          = "FORBIDDEN"
+	CodeCmdNotAllowed      = "CMD_NOT_ALLOWED"
+	CodeArgNotAllowed      = "ARG_NOT_ALLOWED"
+	CodePathEscape         = "PATH_ESCAPE"
+	CodeNotFound           = "NOT_FOUND"
+	CodePermissionDenied   = "PERMISSION_DENIED"
+	CodeIOError            = "IO_ERROR"
+	CodeReadOnly           = "READ_ONLY"
+	CodeOffsetMismatch     = "OFFSET_MISMATCH"
+	CodeFileTooLarge       = "FILE_TOO_LARGE"
+	CodeChecksumMismatch   = "CHECKSUM_MISMATCH"
+	CodeExitNonZero        = "EXIT_NONZERO"
+	CodeTimeout            = "TIMEOUT"
+	CodeKilled             = "KILLED"
+	CodeCancelled          = "CANCELLED"
+	CodeQueueFull          = "QUEUE_FULL"
+	CodeQueueTimeout       = "QUEUE_TIMEOUT"
+	CodeInternal           = "INTERNAL"
+
+	// 클라이언트: 연결/전송 실패
+	CodeBackendUnavailable = "BACKEND_UNAVAILABLE"
+	// 클라이언트: 응답 대기 시간 초과
+	CodeBackendTimeout = "BACKEND_TIMEOUT"
+	// 클라이언트: 서버가 요청 타입을 모르거나 응답을 해석할 수 없음
+	CodeIncompatible = "INCOMPATIBLE_BACKEND"
+)
+
+// 스트림 이벤트 종류
+const (
+	EventStdout = "stdout"
+	EventStderr = "stderr"
+	EventExit   = "exit"
+)
+
+// 스트림 이벤트 스키마
+type Event struct {
+	// 이벤트 종류(stdout/stderr/exit)
+	Event string `json:"event"`
+	// 추적용 ID
+	RequestID string `json:"request_id,omitempty"`
+	// 출력 조각
+	Data string `json:"data,omitempty"`
+	// 최종 결과(exit)
+	Result *Res `json:"result,omitempty"`
 }
 
 // TCP 클라이언트 설정
@@ -58,53 +230,387 @@ type Config struct {
 	Port        string
 	DialTimeout time.Duration
 	IOTimeout   time.Duration
+	// 풀 최대 연결 수
+	MaxConns int
+	// 유휴 연결 정리 기준
+	IdleTimeout time.Duration
+	// 유휴 연결 헬스체크 주기
+	HealthInterval time.Duration
+	// 전송 방식(frame/line)
+	Framing string
+	// 메시지 최대 크기
+	MaxFrame int
+
+	// TLS: 서버 인증서 CA(설정 시 TLS 사용)
+	TLSCAFile string
+	// TLS: 클라이언트 인증서/키(mTLS)
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLS: 서버 인증서 이름(비면 Host)
+	TLSServerName string
+
+	// 요청 서명 키(비면 서명 안 함)
+	HMACKeyID  string
+	HMACSecret string
 }
 
-// TCP 클라이언트
+// TCP 클라이언트(다중화 연결 풀)
 type Client struct {
-	cfg Config
+	cfg  Config
+	pool *pool
+}
+
+func New(cfg Config) (*Client, error) {
+	// 풀 기본값 보정
+	if cfg.MaxConns <= 0 {
+		cfg.MaxConns = defaultMaxConns
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = defaultIdleTimeout
+	}
+	if cfg.HealthInterval <= 0 {
+		cfg.HealthInterval = defaultHealthInterval
+	}
+	if cfg.Framing != FramingLine {
+		cfg.Framing = FramingFrame
+	}
+	if cfg.MaxFrame <= 0 {
+		cfg.MaxFrame = defaultMaxFrame
+	}
+
+	// TLS 설정 로드
+	tc, err := buildTLS(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// 서명기
+	var sg *signer
+	if cfg.HMACKeyID != "" && cfg.HMACSecret != "" {
+		sg = &signer{keyID: cfg.HMACKeyID, key: []byte(cfg.HMACSecret)}
+	}
+	return &Client{cfg: cfg, pool: newPool(cfg, tc, sg)}, nil
+}
+
+// 풀 연결 전부 종료
+func (c *Client) Close() {
+	c.pool.close()
 }
 
-func New(cfg Config) *Client {
-	return &Client{cfg: cfg}
+// 요청 ID 생성
+func newID() string {
+	// 8바이트 랜덤
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// 실패 응답 구성
+func errRes(req Req, err error) Res {
+	return Res{Ok: false, Error: err.Error(), Code: errCode(err), RequestID: req.RequestID, UserID: req.UserID}
+}
+
+// 클라이언트 쪽 실패 → 에러 코드
+func errCode(err error) string {
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeBackendTimeout
+	case errors.Is(err, context.Canceled):
+		return CodeCancelled
+	case errors.Is(err, errDuplicateID):
+		return CodeDuplicateRequestID
+	case errors.Is(err, ErrIncompatible), errors.As(err, &syntaxErr):
+		return CodeIncompatible
+	case errors.Is(err, ErrChecksum):
+		return CodeProtocolError
+	case errors.Is(err, errPoolBusy):
+		return CodeQueueFull
+	default:
+		// dial 실패, 연결 끊김, 수신 버퍼 초과, 풀 종료 등
+		return CodeBackendUnavailable
+	}
+}
+
+// 응답 메시지 → Res(바이너리는 Data로)
+func decodeRes(msg message) (Res, error) {
+	var res Res
+	if err := json.Unmarshal(msg.header, &res); err != nil {
+		return res, err
+	}
+	switch {
+	case len(msg.payload) > 0:
+		// 프레임 모드 payload
+		res.Data = msg.payload
+	case res.FileB64 != "":
+		// 줄 모드 Base64
+		b, err := base64.StdEncoding.DecodeString(res.FileB64)
+		if err != nil {
+			return res, err
+		}
+		res.Data = b
+	}
+	// 원본은 Data로만 전달
+	res.FileB64 = ""
+	return res, nil
+}
+
+// 받은 파일 청크가 손상됨
+var ErrChecksum = errors.New("file chunk checksum mismatch")
+
+// file 응답 청크를 체크섬과 비교(구버전 서버는 값이 없어 통과)
+func verifyChunk(res Res) error {
+	if !res.Ok || (res.CRC32C == "" && res.SHA256 == "") {
+		return nil
+	}
+	crc, sum := Checksums(res.Data)
+	if (res.CRC32C != "" && res.CRC32C != crc) || (res.SHA256 != "" && res.SHA256 != sum) {
+		return ErrChecksum
+	}
+	return nil
+}
+
+// CRC32C(Castagnoli) 테이블
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// 바이트의 CRC32C/SHA-256(hex, TCP 서버와 같은 형식)
+func Checksums(b []byte) (crc, sum string) {
+	s := sha256.Sum256(b)
+	return fmt.Sprintf("%08x", crc32.Checksum(b, castagnoli)), hex.EncodeToString(s[:])
+}
+
+// 요청 전송 후 대기자 반환
+// - get: 연결 선택(공유 풀 또는 스트림 전용)
+// - 재사용 연결이 이미 끊겼거나 그 사이 상한이 차면 다른 연결로 한 번 재시도
+func (c *Client) start(ctx context.Context, req Req, get func(context.Context) (*muxConn, error)) (*muxConn, *waiter, error) {
+	var lastErr error
+	for attempt := 0; attempt < 2; attempt++ {
+		// 연결 선택
+		m, err := get(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// 서버가 모르는 타입은 보내지 않음
+		if !m.supports(req.Type) {
+			c.release(m)
+			return nil, nil, fmt.Errorf("%w: server does not support request type %q", ErrIncompatible, req.Type)
+		}
+
+		// 응답 대기 등록
+		w, err := m.register(req.RequestID)
+		if err != nil {
+			c.release(m)
+			if errors.Is(err, errConnClosed) || errors.Is(err, errPoolBusy) {
+				lastErr = err
+				continue
+			}
+			return nil, nil, err
+		}
+		m.touch()
+
+		// 요청 원본 바이트(프레임 모드는 payload, 줄 모드는 data_b64)
+		var payload []byte
+		if len(req.Data) > 0 {
+			if m.codec.binary() {
+				payload = req.Data
+			} else {
+				req.DataB64 = base64.StdEncoding.EncodeToString(req.Data)
+			}
+		}
+
+		// 서명 + 전송
+		b, err := c.pool.sign.encode(req, payload)
+		if err != nil {
+			m.unregister(req.RequestID)
+			c.release(m)
+			return nil, nil, err
+		}
+		if err := m.send(b, payload, c.cfg.IOTimeout); err != nil {
+			m.unregister(req.RequestID)
+			m.close(err)
+			c.release(m)
+			lastErr = err
+			continue
+		}
+		return m, w, nil
+	}
+	return nil, nil, lastErr
+}
+
+// 요청 종료 처리
+func (c *Client) finish(m *muxConn, req Req) {
+	m.unregister(req.RequestID)
+	m.touch()
+	c.release(m)
+}
+
+// 스트림 전용 연결은 요청이 끝나면 닫음(공유 연결은 풀에 남김)
+func (c *Client) release(m *muxConn) {
+	if m.exclusive {
+		c.pool.drop(m)
+	}
+}
+
+// 서버에 취소 전달(실행중 프로세스 kill, 결과는 원래 요청으로 도착)
+func (c *Client) cancel(m *muxConn, req Req) {
+	if !m.supports("cancel") {
+		return
+	}
+	b, err := c.pool.sign.encode(Req{RequestID: req.RequestID, UserID: req.UserID, Type: "cancel"}, nil)
+	if err != nil {
+		return
+	}
+	_ = m.send(b, nil, c.cfg.IOTimeout)
+}
+
+// 전송 후 응답 기한: IOTimeout + 서버 실행 대기 상한 + 실행 제한시간
+func (c *Client) replyTimeout(m *muxConn, req Req) time.Duration {
+	return c.cfg.IOTimeout + m.queueTimeout + time.Duration(req.TimeoutMs)*time.Millisecond
+}
+
+// 취소 후 서버의 최종 결과를 잠시 기다릴 context
+func cancelWait() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), cancelGrace)
 }
 
 // TCP 서버에 명령을 보내고 응답을 받는 함수
 // - context를 통해 요청 취소/타임아웃 전파
+// - 풀의 연결을 공유하며 응답은 request_id로 구분
 func (c *Client) Call(ctx context.Context, req Req) Res {
-	addr := c.cfg.Host + ":" + c.cfg.Port
+	// 다중화용 ID 보장
+	if req.RequestID == "" {
+		req.RequestID = newID()
+	}
 
-	// TCP 연결 (Context + 연결 타임아웃 적용)
-	dialer := net.Dialer{Timeout: c.cfg.DialTimeout}
-	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	// 연결 확보 + 전송
+	sctx, scancel := context.WithTimeout(ctx, c.cfg.IOTimeout)
+	m, w, err := c.start(sctx, req, c.pool.get)
+	scancel()
 	if err != nil {
-		return Res{Ok: false, Error: err.Error(), RequestID: req.RequestID, UserID: req.UserID}
+		return errRes(req, err)
 	}
-	defer conn.Close()
+	defer c.finish(m, req)
 
-	// TCP 읽기/쓰기 전체 타임아웃 설정
-	_ = conn.SetDeadline(time.Now().Add(c.cfg.IOTimeout))
+	// 읽기 전체 타임아웃(서버 실행 대기 + 실행 제한시간만큼 연장)
+	ctx, cancel := context.WithTimeout(ctx, c.replyTimeout(m, req))
+	defer cancel()
 
-	// 요청 JSON 생성
-	b, _ := json.Marshal(req)
+	// 응답 한 건 수신
+	msg, err := m.recv(ctx, w)
+	if err != nil && ctx.Err() != nil {
+		// 호출자가 떠났거나 시간이 다 됨 → 서버 실행도 중단
+		c.cancel(m, req)
 
-	// 한 줄(JSON + '\n') 프로토콜로 전송
-	if _, err := conn.Write(append(b, '\n')); err != nil {
-		return Res{Ok: false, Error: err.Error(), RequestID: req.RequestID, UserID: req.UserID}
+		// 호출자 취소면 서버의 cancelled 결과를 받아 반환(로그 기록용)
+		if errors.Is(err, context.Canceled) {
+			wctx, wcancel := cancelWait()
+			defer wcancel()
+			if msg, werr := m.recv(wctx, w); werr == nil {
+				if res, derr := decodeRes(msg); derr == nil {
+					return res
+				}
+			}
+		}
+	}
+	if errors.Is(err, errOverflow) {
+		// 결과를 받을 수 없으므로 서버 실행도 중단
+		c.cancel(m, req)
+	}
+	if err != nil {
+		return errRes(req, err)
 	}
 
-	// 응답 한 줄 수신
-	br := bufio.NewReader(conn)
-	line, err := br.ReadBytes('\n')
+	// 메시지 → Res 파싱
+	res, err := decodeRes(msg)
 	if err != nil {
-		return Res{Ok: false, Error: err.Error(), RequestID: req.RequestID, UserID: req.UserID}
+		return errRes(req, err)
 	}
 
-	// JSON → Res 파싱
-	var res Res
-	if err := json.Unmarshal(line, &res); err != nil {
-		return Res{Ok: false, Error: err.Error(), RequestID: req.RequestID, UserID: req.UserID}
+	// 파일 청크 무결성
+	if req.Type == "file" {
+		if err := verifyChunk(res); err != nil {
+			return errRes(req, err)
+		}
 	}
 
 	return res
 }
+
+// 출력을 이벤트로 받는 실행 요청
+// - 채널은 exit 이벤트(실패 시 합성된 exit) 후 닫힘
+func (c *Client) Stream(ctx context.Context, req Req) <-chan Event {
+	req.Stream = true
+	if req.RequestID == "" {
+		req.RequestID = newID()
+	}
+	ch := make(chan Event, 16)
+
+	// 실패를 exit 이벤트로 변환
+	fail := func(err error) Event {
+		res := errRes(req, err)
+		return Event{Event: EventExit, RequestID: req.RequestID, Result: &res}
+	}
+
+	// 소비자가 떠나도 막히지 않게 전달(취소 후에는 유예 시간까지)
+	deliver := ctx
+	send := func(ev Event) bool {
+		select {
+		case ch <- ev:
+			return true
+		case <-deliver.Done():
+			return false
+		}
+	}
+
+	go func() {
+		defer close(ch)
+
+		// 전용 연결(소비자가 느리면 서버 출력도 함께 대기)
+		sctx, scancel := context.WithTimeout(ctx, c.cfg.IOTimeout)
+		m, w, err := c.start(sctx, req, c.pool.dedicated)
+		scancel()
+		if err != nil {
+			send(fail(err))
+			return
+		}
+		defer c.finish(m, req)
+
+		// 전체 타임아웃(서버 실행 대기 + 실행 제한시간만큼 연장)
+		ctx, cancel := context.WithTimeout(ctx, c.replyTimeout(m, req))
+		defer cancel()
+
+		// exit까지 이벤트 수신
+		recvCtx := ctx
+		for {
+			msg, err := m.recv(recvCtx, w)
+			if err != nil && recvCtx == ctx && ctx.Err() != nil {
+				// 서버 실행 중단
+				c.cancel(m, req)
+
+				// 호출자 취소면 서버의 cancelled exit까지 받아 전달
+				if errors.Is(err, context.Canceled) {
+					wctx, wcancel := cancelWait()
+					defer wcancel()
+					recvCtx, deliver = wctx, wctx
+					continue
+				}
+			}
+			if err != nil {
+				send(fail(err))
+				return
+			}
+
+			var ev Event
+			if err := json.Unmarshal(msg.header, &ev); err != nil {
+				send(fail(err))
+				return
+			}
+
+			if !send(ev) || ev.Event == EventExit {
+				return
+			}
+		}
+	}()
+
+	return ch
+}
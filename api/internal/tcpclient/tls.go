@@ -0,0 +1,49 @@
+package tcpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// TCP 서버 검증용 tls.Config(CAFile이 비면 평문)
+func buildTLS(cfg Config) (*tls.Config, error) {
+	if cfg.TLSCAFile == "" {
+		if cfg.TLSCertFile != "" {
+			return nil, errors.New("tls: client cert needs a ca file")
+		}
+		return nil, nil
+	}
+
+	// 서버 인증서 CA
+	pem, err := os.ReadFile(cfg.TLSCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("tls: read ca: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("tls: no certificates in ca file")
+	}
+
+	tc := &tls.Config{
+		RootCAs:    pool,
+		ServerName: cfg.TLSServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+	// 서버 이름 기본값은 접속 호스트
+	if tc.ServerName == "" {
+		tc.ServerName = cfg.Host
+	}
+
+	// 클라이언트 인증서(mTLS)
+	if cfg.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: load client key pair: %w", err)
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+	return tc, nil
+}
@@ -1,12 +1,267 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"golang-network-labs/tcp/internal/auth"
+	"golang-network-labs/tcp/internal/authz"
+	"golang-network-labs/tcp/internal/execx"
+	"golang-network-labs/tcp/internal/filex"
+	"golang-network-labs/tcp/internal/protocol"
 	"golang-network-labs/tcp/internal/server"
 )
 
+// 초 단위 환경변수 → Duration
+func envSeconds(key string, def time.Duration) time.Duration {
+	// 공백 제거
+	v := strings.TrimSpace(os.Getenv(key))
+	// 없으면 기본값
+	if v == "" {
+		return def
+	}
+	// 정수 파싱
+	n, err := strconv.Atoi(v)
+	// 실패/0이하면 기본값
+	if err != nil || n <= 0 {
+		return def
+	}
+	return time.Duration(n) * time.Second
+}
+
+// 정수 환경변수
+func envInt(key string, def int) int {
+	// 공백 제거
+	v := strings.TrimSpace(os.Getenv(key))
+	// 없으면 기본값
+	if v == "" {
+		return def
+	}
+	// 정수 파싱
+	n, err := strconv.Atoi(v)
+	// 실패/0이하면 기본값
+	if err != nil || n <= 0 {
+		return def
+	}
+	return n
+}
+
+// 콤마 구분 환경변수
+func envList(key string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// "kid:secret" 목록 → 키 맵
+func hmacKeys(pairs []string) map[string][]byte {
+	keys := make(map[string][]byte, len(pairs))
+	for _, p := range pairs {
+		id, secret, ok := strings.Cut(p, ":")
+		if !ok || id == "" || secret == "" {
+			log.Fatalf("HMAC_KEYS entry %q must be key_id:secret", p)
+		}
+		keys[id] = []byte(secret)
+	}
+	return keys
+}
+
+// 파일 루트 설정(FILE_ROOTS가 잘못되면 기동 거절)
+func configureRoots() {
+	spec := strings.TrimSpace(os.Getenv("FILE_ROOTS"))
+	explicit := spec != ""
+	if !explicit {
+		spec = filex.DefaultRoots
+	}
+
+	list, err := filex.Configure(spec)
+	if err != nil {
+		// 기본값(/data)이 없는 환경은 파일 요청만 NOT_FOUND
+		if !explicit {
+			log.Printf("file roots: %v (file requests will fail)", err)
+			return
+		}
+		log.Fatalf("FILE_ROOTS: %v", err)
+	}
+	for _, r := range list {
+		log.Printf("file root %s=%s (%s)", r.Name, r.Dir, r.Mode())
+	}
+
+	// 업로드 파일 하나의 최대 크기
+	filex.MaxUpload = int64(envInt("FILE_MAX_UPLOAD_BYTES", int(filex.MaxUpload)))
+
+	// 명령 경로 인자는 기본 루트 기준
+	execx.SetRoot(list[0].Dir)
+}
+
+// 정책 파일 적용(실패면 기존 정책 유지)
+func applyPolicy(path, why string) error {
+	ps, err := execx.LoadPolicy(path)
+	if err != nil {
+		return err
+	}
+
+	// 내용이 같으면 교체 안 함
+	prev := execx.CurrentPolicy()
+	if ps.Version == prev.Version && ps.Source == prev.Source {
+		log.Printf("policy unchanged (%s) version=%s", why, ps.Version)
+		return nil
+	}
+	execx.SetPolicy(ps)
+	log.Printf("policy loaded (%s) source=%s version=%s commands=%s",
+		why, ps.Source, ps.Version, strings.Join(ps.Commands(), ","))
+
+	// 실행 파일이 없는 명령은 경고만
+	if missing := ps.Missing(); len(missing) > 0 {
+		log.Printf("policy: not found in PATH: %s", strings.Join(missing, ","))
+	}
+	return nil
+}
+
+// 정책 파일 변경 감지용 상태
+type fileStamp struct {
+	mod  time.Time
+	size int64
+}
+
+// 파일 상태(없으면 빈 값)
+func stampOf(path string) fileStamp {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{mod: fi.ModTime(), size: fi.Size()}
+}
+
+// 권한 파일 적용(실패면 기존 규칙 유지)
+func applyAuthz(path, why string) error {
+	rules, err := authz.Load(path)
+	if err != nil {
+		return err
+	}
+
+	// 내용이 같으면 교체 안 함
+	if prev := authz.Current(); prev != nil && rules.Version == prev.Version {
+		log.Printf("authz unchanged (%s) version=%s", why, rules.Version)
+		return nil
+	}
+	authz.Set(rules)
+	log.Printf("authz loaded (%s) source=%s version=%s users=%s",
+		why, rules.Source, rules.Version, strings.Join(rules.Users(), ","))
+	return nil
+}
+
+// 다시 읽을 설정 파일
+type reloadable struct {
+	// 로그용 이름(policy/authz)
+	name  string
+	path  string
+	apply func(path, why string) error
+	// 마지막으로 본 파일 상태
+	last fileStamp
+}
+
+// SIGHUP 또는 파일 변경 시 설정 다시 읽기(연결은 유지)
+func watchFiles(ctx context.Context, files []*reloadable, every time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	// 설정 파일이 없으면 SIGHUP만 받아 무시
+	var tick <-chan time.Time
+	if len(files) > 0 {
+		t := time.NewTicker(every)
+		defer t.Stop()
+		tick = t.C
+	}
+	for _, f := range files {
+		f.last = stampOf(f.path)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			if len(files) == 0 {
+				log.Println("SIGHUP: no POLICY_FILE/AUTHZ_FILE set, nothing to reload")
+			}
+			for _, f := range files {
+				f.last = stampOf(f.path)
+				reload(f, "SIGHUP")
+			}
+		case <-tick:
+			for _, f := range files {
+				st := stampOf(f.path)
+				if st == f.last {
+					continue
+				}
+				f.last = st
+				reload(f, "file changed")
+			}
+		}
+	}
+}
+
+// 파일 한 개 다시 읽기(실패는 로그만)
+func reload(f *reloadable, why string) {
+	if err := f.apply(f.path, why); err != nil {
+		log.Printf("%s reload failed, keeping previous: %v", f.name, err)
+	}
+}
+
+// 집계가 바뀐 경우에만 주기적으로 로그
+func watchStats(ctx context.Context, s *server.Server, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+
+	var last server.Stats
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		if st := s.Stats(); st != last {
+			logStats(st)
+			last = st
+		}
+	}
+}
+
+// 방치된 업로드 임시 파일 주기 정리(쓰기 요청이 없어도 삭제)
+func sweepUploads(ctx context.Context, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		filex.SweepUploads()
+	}
+}
+
+// 연결 집계 한 줄
+func logStats(st server.Stats) {
+	log.Printf("conns=%d rejected_max=%d rejected_per_ip=%d header_timeouts=%d read_timeouts=%d idle_timeouts=%d write_timeouts=%d exec_running=%d exec_queued=%d",
+		st.Conns, st.RejectedMaxConns, st.RejectedPerIP, st.HeaderTimeouts, st.ReadTimeouts, st.IdleTimeouts, st.WriteTimeouts,
+		st.ExecRunning, st.ExecQueued)
+}
+
 func main() {
 	// 리슨 포트 기본값
 	port := os.Getenv("TCP_PORT")
@@ -15,17 +270,109 @@ func main() {
 		port = "9000"
 	}
 
+	// 파일 루트(권한 규칙이 루트 이름을 쓰므로 먼저)
+	configureRoots()
+
+	// 명령 정책 + 사용자 권한(파일이 잘못되면 기동 거절)
+	var files []*reloadable
+	if path := strings.TrimSpace(os.Getenv("POLICY_FILE")); path != "" {
+		files = append(files, &reloadable{name: "policy", path: path, apply: applyPolicy})
+	} else {
+		log.Printf("policy: builtin version=%s", execx.CurrentPolicy().Version)
+	}
+	if path := strings.TrimSpace(os.Getenv("AUTHZ_FILE")); path != "" {
+		files = append(files, &reloadable{name: "authz", path: path, apply: applyAuthz})
+	} else {
+		log.Println("authz: AUTHZ_FILE not set, every user may use every request type")
+	}
+	for _, f := range files {
+		if err := f.apply(f.path, "startup"); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// 채널 인증(서명 또는 mTLS)이 없으면 user_id를 믿을 수 없어 권한 검사가 무의미
+	keys := hmacKeys(envList("HMAC_KEYS"))
+	tlsCfg := server.TLSConfig{
+		CertFile:       strings.TrimSpace(os.Getenv("TLS_CERT_FILE")),
+		KeyFile:        strings.TrimSpace(os.Getenv("TLS_KEY_FILE")),
+		ClientCAFile:   strings.TrimSpace(os.Getenv("TLS_CLIENT_CA_FILE")),
+		AllowedClients: envList("TLS_ALLOWED_CLIENTS"),
+	}
+	mtls := tlsCfg.CertFile != "" && tlsCfg.ClientCAFile != ""
+	if strings.TrimSpace(os.Getenv("AUTHZ_FILE")) != "" && len(keys) == 0 && !mtls {
+		log.Fatal("AUTHZ_FILE requires HMAC_KEYS or mTLS (TLS_CERT_FILE + TLS_CLIENT_CA_FILE): user_id is unauthenticated otherwise")
+	}
+
 	// 서버 생성
 	s := server.New(server.Config{
 		Addr: ":" + port,
+		Exec: execx.Options{
+			DefaultTimeout: envSeconds("EXEC_TIMEOUT_SEC", execx.DefaultOptions.DefaultTimeout),
+			MaxTimeout:     envSeconds("EXEC_MAX_TIMEOUT_SEC", execx.DefaultOptions.MaxTimeout),
+			MaxOutput:      envInt("EXEC_MAX_OUTPUT_BYTES", execx.DefaultOptions.MaxOutput),
+			Workers:        envInt("EXEC_WORKERS", 0),
+			QueueSize:      envInt("EXEC_QUEUE_SIZE", 0),
+			QueueTimeout:   envSeconds("EXEC_QUEUE_TIMEOUT_SEC", 0),
+		},
+		MaxFrame: envInt("MAX_FRAME_BYTES", protocol.DefaultMaxFrame),
+		TLS:      tlsCfg,
+		Auth: auth.Config{
+			Keys:   keys,
+			Window: envSeconds("HMAC_WINDOW_SEC", auth.DefaultWindow),
+		},
+		MaxConns:      envInt("MAX_CONNS", server.DefaultMaxConns),
+		MaxConnsPerIP: envInt("MAX_CONNS_PER_IP", server.DefaultMaxConnsPerIP),
+		HeaderTimeout: envSeconds("HEADER_TIMEOUT_SEC", 0),
+		ReadTimeout:   envSeconds("READ_TIMEOUT_SEC", 0),
+		IdleTimeout:   envSeconds("IDLE_TIMEOUT_SEC", 0),
+		WriteTimeout:  envSeconds("WRITE_TIMEOUT_SEC", 0),
 	})
 
 	// 시작 로그
-	log.Println("tcp :", port)
+	if os.Getenv("TLS_CERT_FILE") != "" {
+		log.Println("tcp (tls) :", port)
+	} else {
+		log.Println("tcp :", port)
+	}
 
 	// 서버 실행
-	if err := s.ListenAndServe(); err != nil {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.ListenAndServe()
+	}()
+
+	// 종료 시그널 대기
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// 정책/권한 다시 읽기
+	go watchFiles(ctx, files, envSeconds("POLICY_WATCH_INTERVAL_SEC", 5*time.Second))
+
+	// 연결 집계 주기 로그
+	go watchStats(ctx, s, envSeconds("STATS_LOG_INTERVAL_SEC", time.Minute))
+
+	// 업로드 만료 정리
+	go sweepUploads(ctx, time.Minute)
+
+	select {
+	case err := <-errCh:
 		// 치명 에러면 종료
 		log.Fatal(err)
+	case <-ctx.Done():
+		log.Println("shutdown signal received")
+	}
+
+	// 처리중 요청 정리(기한 넘으면 kill)
+	timeout := envSeconds("SHUTDOWN_TIMEOUT_SEC", 20*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := s.Shutdown(shutdownCtx); err != nil {
+		log.Printf("shutdown: %v (in-flight requests killed)", err)
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, server.ErrServerClosed) {
+		log.Printf("serve: %v", err)
 	}
+	logStats(s.Stats())
+	log.Println("tcp server stopped")
 }
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// 허용 시각 오차 기본값
+const DefaultWindow = 30 * time.Second
+
+// nonce 캐시 상한(넘치면 만료분 정리 후에도 가득 차면 거절)
+const maxNonces = 100000
+
+// 검증 실패 사유
+var (
+	ErrUnsigned   = errors.New("request not signed")
+	ErrUnknownKey = errors.New("unknown key id")
+	ErrStale      = errors.New("stale timestamp")
+	ErrBadSig     = errors.New("bad signature")
+	ErrReplay     = errors.New("replayed nonce")
+	ErrBusy       = errors.New("nonce cache full")
+)
+
+// 서명 설정
+type Config struct {
+	// key_id → 비밀키(여러 개면 교체 기간 동안 모두 허용)
+	Keys map[string][]byte
+	// 시각 오차 + nonce 보관 기간
+	Window time.Duration
+}
+
+// 서명 필드(요청 JSON 안에 포함)
+type Signed struct {
+	KeyID string `json:"key_id"`
+	// 서명 시각(unix ms)
+	Ts    int64  `json:"ts"`
+	Nonce string `json:"nonce"`
+	Sig   string `json:"sig"`
+}
+
+// 요청 서명 검증기
+type Verifier struct {
+	keys   map[string][]byte
+	window time.Duration
+
+	// 사용된 nonce → 만료 시각
+	mu     sync.Mutex
+	nonces map[string]time.Time
+}
+
+// 검증기 생성(키가 없으면 nil → 서명 검사 안 함)
+func New(cfg Config) *Verifier {
+	if len(cfg.Keys) == 0 {
+		return nil
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultWindow
+	}
+	return &Verifier{
+		keys:   cfg.Keys,
+		window: cfg.Window,
+		nonces: make(map[string]time.Time),
+	}
+}
+
+// 요청 한 건 검증
+// - header: 수신한 JSON 원문, payload: 프레임 바이너리
+func (v *Verifier) Verify(header, payload []byte) error {
+	var s Signed
+	if err := json.Unmarshal(header, &s); err != nil {
+		return err
+	}
+	if s.KeyID == "" || s.Sig == "" || s.Nonce == "" || s.Ts == 0 {
+		return ErrUnsigned
+	}
+
+	// 키 조회
+	key, ok := v.keys[s.KeyID]
+	if !ok {
+		return ErrUnknownKey
+	}
+
+	// 시각 오차 검사
+	now := time.Now()
+	ts := time.UnixMilli(s.Ts)
+	if ts.Before(now.Add(-v.window)) || ts.After(now.Add(v.window)) {
+		return ErrStale
+	}
+
+	// 서명 검사(nonce 기록 전에 해야 캐시 오염 방지)
+	want, err := Sign(key, header, payload)
+	if err != nil {
+		return err
+	}
+	got, err := hex.DecodeString(s.Sig)
+	if err != nil || !hmac.Equal(got, want) {
+		return ErrBadSig
+	}
+
+	// 재사용 검사
+	return v.remember(s.KeyID+":"+s.Nonce, now)
+}
+
+// nonce 기록(창 안에서 두 번째면 거절)
+func (v *Verifier) remember(id string, now time.Time) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if exp, seen := v.nonces[id]; seen && now.Before(exp) {
+		return ErrReplay
+	}
+
+	// 가득 차면 만료분 정리
+	if len(v.nonces) >= maxNonces {
+		for k, exp := range v.nonces {
+			if !now.Before(exp) {
+				delete(v.nonces, k)
+			}
+		}
+		if len(v.nonces) >= maxNonces {
+			return ErrBusy
+		}
+	}
+
+	// 앞뒤 오차만큼 보관하면 창 안의 재전송은 모두 걸림
+	v.nonces[id] = now.Add(2 * v.window)
+	return nil
+}
+
+// 서명 값 계산: HMAC-SHA256(key, canonical(header) || payload)
+func Sign(key, header, payload []byte) ([]byte, error) {
+	c, err := Canonical(header)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(c)
+	mac.Write(payload)
+	return mac.Sum(nil), nil
+}
+
+// 서명 대상 JSON: sig를 뺀 뒤 키 정렬 + 공백 제거
+func Canonical(header []byte) ([]byte, error) {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(header, &m); err != nil {
+		return nil, err
+	}
+	delete(m, "sig")
+	// map은 키 정렬, RawMessage는 compact되어 직렬화됨
+	return json.Marshal(m)
+}
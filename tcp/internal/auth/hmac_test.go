@@ -0,0 +1,250 @@
+package auth
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 알려진 답 벡터(api/internal/tcpclient 서명기 출력, 클라이언트 sign_test.go와 같은 값)
+const vectorKeyID, vectorKey = "k1", "test-secret"
+
+var hmacVectors = []struct {
+	name      string
+	header    string
+	payload   string
+	canonical string
+}{
+	{
+		name:      "cmd",
+		header:    `{"request_id":"r1","user_id":"alice","type":"cmd","cmd":"ls -la logs","timeout_ms":1500,"key_id":"k1","ts":1792220955937,"nonce":"cf460b6f3cfb5304fc569299542af488","sig":"9db697c0f9d8a5bef8fd83f371f1b8d9a51dcb0ea9111724958d28596b74bec3"}`,
+		payload:   "",
+		canonical: `{"cmd":"ls -la logs","key_id":"k1","nonce":"cf460b6f3cfb5304fc569299542af488","request_id":"r1","timeout_ms":1500,"ts":1792220955937,"type":"cmd","user_id":"alice"}`,
+	},
+	{
+		name:      "unicode and escapes",
+		header:    `{"request_id":"r2","user_id":"김철수","type":"list","path":"data:a/\u003cb\u003e\u0026\"c\"","pattern":"*.log","key_id":"k1","ts":1792220955937,"nonce":"43b39c9774fa4568b658399dd9fc9b4a","sig":"e8764217e96ec0a8ee50970ea54a68a2f658ed65db6ad3aea8f6527d584f8d2e"}`,
+		payload:   "",
+		canonical: `{"key_id":"k1","nonce":"43b39c9774fa4568b658399dd9fc9b4a","path":"data:a/\u003cb\u003e\u0026\"c\"","pattern":"*.log","request_id":"r2","ts":1792220955937,"type":"list","user_id":"김철수"}`,
+	},
+	{
+		name:      "write with payload",
+		header:    `{"request_id":"r3","user_id":"ci","type":"write","offset":4096,"op":"append","upload_id":"u1","key_id":"k1","ts":1792220955937,"nonce":"93c5a107dbe5f5ee8576b1492de96453","sig":"338a584afed2bb32562e10cd908907aa38f121cee720c5e0dce33a5cf16b4c44"}`,
+		payload:   "\x00\xf7\xff\nx",
+		canonical: `{"key_id":"k1","nonce":"93c5a107dbe5f5ee8576b1492de96453","offset":4096,"op":"append","request_id":"r3","ts":1792220955937,"type":"write","upload_id":"u1","user_id":"ci"}`,
+	},
+}
+
+// 벡터 시각과 무관하게 검증하는 검증기(시각 검사는 별도 테스트)
+func vectorVerifier() *Verifier {
+	return New(Config{Keys: map[string][]byte{vectorKeyID: []byte(vectorKey)}, Window: 100 * 365 * 24 * time.Hour})
+}
+
+// 서명된 요청 생성(클라이언트와 같은 방식: sig 없이 서명 후 추가)
+func signReq(t *testing.T, key []byte, fields map[string]any, payload []byte) []byte {
+	t.Helper()
+	delete(fields, "sig")
+	b, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := Sign(key, b, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields["sig"] = hex.EncodeToString(sig)
+	out, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// JSON 필드 하나 바꾸기(서명은 그대로)
+func withField(t *testing.T, header []byte, key string, v any) []byte {
+	t.Helper()
+	var m map[string]any
+	if err := json.Unmarshal(header, &m); err != nil {
+		t.Fatal(err)
+	}
+	if v == nil {
+		delete(m, key)
+	} else {
+		m[key] = v
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestVerifyKnownAnswers(t *testing.T) {
+	v := vectorVerifier()
+
+	for _, tc := range hmacVectors {
+		t.Run(tc.name, func(t *testing.T) {
+			header := []byte(tc.header)
+			payload := []byte(tc.payload)
+
+			// 클라이언트와 같은 정규화
+			c, err := Canonical(header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(c) != tc.canonical {
+				t.Fatalf("canonical = %s, want %s", c, tc.canonical)
+			}
+
+			// 클라이언트 서명 → 서버 검증
+			if err := v.Verify(header, payload); err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			// 같은 요청 재전송
+			if err := v.Verify(header, payload); !errors.Is(err, ErrReplay) {
+				t.Fatalf("replay err = %v, want %v", err, ErrReplay)
+			}
+		})
+	}
+}
+
+func TestVerifyTampered(t *testing.T) {
+	header := []byte(hmacVectors[0].header)
+	write := []byte(hmacVectors[2].header)
+
+	cases := []struct {
+		name    string
+		header  []byte
+		payload []byte
+		want    error
+	}{
+		{"cmd changed", withField(t, header, "cmd", "rm -rf /"), nil, ErrBadSig},
+		{"user changed", withField(t, header, "user_id", "root"), nil, ErrBadSig},
+		{"type changed", withField(t, header, "type", "file"), nil, ErrBadSig},
+		{"request id changed", withField(t, header, "request_id", "r9"), nil, ErrBadSig},
+		{"timeout changed", withField(t, header, "timeout_ms", 999999), nil, ErrBadSig},
+		{"field added", withField(t, header, "path", "data:"), nil, ErrBadSig},
+		{"field removed", withField(t, header, "timeout_ms", nil), nil, ErrBadSig},
+		{"ts changed", withField(t, header, "ts", 1), nil, ErrBadSig},
+		{"nonce changed", withField(t, header, "nonce", "00"), nil, ErrBadSig},
+		{"sig changed", withField(t, header, "sig", strings.Repeat("0", 64)), nil, ErrBadSig},
+		{"sig not hex", withField(t, header, "sig", "zz"), nil, ErrBadSig},
+		{"payload appended", header, []byte("x"), ErrBadSig},
+		{"payload changed", write, []byte{0x00, 0xf7, 0xff, '\n', 'y'}, ErrBadSig},
+		{"payload dropped", write, nil, ErrBadSig},
+		{"unknown key", withField(t, header, "key_id", "k2"), nil, ErrUnknownKey},
+		{"sig missing", withField(t, header, "sig", nil), nil, ErrUnsigned},
+		{"nonce missing", withField(t, header, "nonce", nil), nil, ErrUnsigned},
+		{"ts missing", withField(t, header, "ts", nil), nil, ErrUnsigned},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := vectorVerifier()
+			if err := v.Verify(tc.header, tc.payload); !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+
+	// 실패한 요청은 nonce를 소모하지 않음
+	v := vectorVerifier()
+	if err := v.Verify(withField(t, header, "cmd", "id"), nil); !errors.Is(err, ErrBadSig) {
+		t.Fatalf("tampered err = %v", err)
+	}
+	if err := v.Verify(header, nil); err != nil {
+		t.Fatalf("original after tampered copy: %v", err)
+	}
+}
+
+func TestVerifyStale(t *testing.T) {
+	key := []byte("k")
+	v := New(Config{Keys: map[string][]byte{"k1": key}, Window: 30 * time.Second})
+	now := time.Now()
+
+	cases := []struct {
+		name string
+		ts   time.Time
+		want error
+	}{
+		{"now", now, nil},
+		{"inside past window", now.Add(-20 * time.Second), nil},
+		{"inside future window", now.Add(20 * time.Second), nil},
+		{"too old", now.Add(-time.Minute), ErrStale},
+		{"too far ahead", now.Add(time.Minute), ErrStale},
+	}
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := signReq(t, key, map[string]any{
+				"request_id": "r", "type": "ping", "key_id": "k1",
+				"ts": tc.ts.UnixMilli(), "nonce": hex.EncodeToString([]byte{byte(i)}),
+			}, nil)
+			if err := v.Verify(h, nil); !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestVerifyReplay(t *testing.T) {
+	k1, k2 := []byte("one"), []byte("two")
+	v := New(Config{Keys: map[string][]byte{"k1": k1, "k2": k2}, Window: time.Minute})
+	ts := time.Now().UnixMilli()
+
+	req := func(key []byte, keyID, nonce, id string) []byte {
+		return signReq(t, key, map[string]any{
+			"request_id": id, "type": "cmd", "cmd": "id", "key_id": keyID, "ts": ts, "nonce": nonce,
+		}, nil)
+	}
+
+	if err := v.Verify(req(k1, "k1", "n1", "a"), nil); err != nil {
+		t.Fatalf("first: %v", err)
+	}
+	// 같은 nonce는 본문이 달라도(새로 서명해도) 재사용으로 거절
+	if err := v.Verify(req(k1, "k1", "n1", "b"), nil); !errors.Is(err, ErrReplay) {
+		t.Fatalf("same nonce err = %v, want %v", err, ErrReplay)
+	}
+	// 새 nonce는 허용
+	if err := v.Verify(req(k1, "k1", "n2", "a"), nil); err != nil {
+		t.Fatalf("new nonce: %v", err)
+	}
+	// nonce는 키별로 구분
+	if err := v.Verify(req(k2, "k2", "n1", "a"), nil); err != nil {
+		t.Fatalf("same nonce other key: %v", err)
+	}
+
+	// 보관 기간이 지나면 nonce 기록 만료(시각 검사로 이미 거절되는 범위)
+	if err := v.remember("k1:n3", time.Now().Add(-3*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.remember("k1:n3", time.Now()); err != nil {
+		t.Fatalf("expired nonce: %v", err)
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	// 키 순서/공백/sig 유무와 무관하게 같은 바이트
+	a, err := Canonical([]byte(`{"ts":1,"cmd":"id","sig":"ab","user_id":"u"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Canonical([]byte(" {\n \"user_id\" : \"u\", \"cmd\":\"id\" ,\"ts\": 1 }"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(a) != string(b) || string(a) != `{"cmd":"id","ts":1,"user_id":"u"}` {
+		t.Fatalf("canonical = %s / %s", a, b)
+	}
+	if _, err := Canonical([]byte("not json")); err == nil {
+		t.Fatal("invalid json accepted")
+	}
+}
+
+func TestNewWithoutKeys(t *testing.T) {
+	// 키가 없으면 검사 안 함(nil)
+	if v := New(Config{}); v != nil {
+		t.Fatal("verifier without keys")
+	}
+}
@@ -0,0 +1,297 @@
+package authz
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang-network-labs/tcp/internal/filex"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 모든 값 허용
+const Any = "*"
+
+// 권한 파일 최대 크기
+const maxFile = 1 << 20
+
+// 권한 검사 대상 요청 타입(hello/ping/cancel은 연결 제어라 항상 허용)
+var checkedTypes = map[string]bool{"cmd": true, "file": true, "list": true, "stat": true, "hash": true, "write": true, "policy": true}
+
+// 권한 거절(errors.Is로 판별)
+var ErrForbidden = errors.New("forbidden")
+
+// 권한 파일 스키마(YAML/JSON 공통)
+type File struct {
+	// 역할 → 허용 범위
+	Roles map[string]Role `json:"roles" yaml:"roles"`
+	// user_id → 역할 목록("*"는 목록에 없는 사용자)
+	Users map[string][]string `json:"users" yaml:"users"`
+}
+
+// 역할 하나의 허용 범위("*"는 전부)
+type Role struct {
+	// 요청 타입(cmd/file/list/stat/write/policy)
+	Types []string `json:"types" yaml:"types"`
+	// 실행 가능한 명령(allowlist 안에서 추가로 제한)
+	Commands []string `json:"commands" yaml:"commands"`
+	// 읽기/쓰기 가능한 경로("/a"는 기본 루트, "logs:/a"는 이름 붙은 루트, "*"는 전부)
+	FileRoots []string `json:"file_roots" yaml:"file_roots"`
+}
+
+// 검증이 끝난 규칙(교체만 하고 수정하지 않음, nil이면 검사 안 함)
+type Rules struct {
+	// 규칙 내용 해시("sha256:...")
+	Version string
+	// 출처(파일 경로)
+	Source string
+	// 적용 시각
+	LoadedAt time.Time
+
+	// user_id → 합쳐진 허용 범위
+	users map[string]*grant
+}
+
+// 사용자 한 명의 허용 범위(역할 합집합)
+type grant struct {
+	roles    []string
+	types    map[string]bool
+	commands map[string]bool
+	roots    []string
+}
+
+// 거절 상세(로그/응답용)
+type Denied struct {
+	User string
+	// 거절된 대상("type file", "command ls", "path /etc")
+	What string
+	// 사용자의 역할
+	Roles []string
+}
+
+func (e *Denied) Error() string {
+	return fmt.Sprintf("forbidden: user %q may not use %s", e.User, e.What)
+}
+
+func (e *Denied) Is(target error) bool { return target == ErrForbidden }
+
+// 적용중인 규칙(nil이면 권한 검사 안 함)
+var active atomic.Pointer[Rules]
+
+// 적용중인 규칙
+func Current() *Rules { return active.Load() }
+
+// 규칙 교체(이미 처리중인 요청은 그대로)
+func Set(r *Rules) { active.Store(r) }
+
+// 파일에서 규칙 읽기 + 검증
+func Load(path string) (*Rules, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// 크기 제한
+	data, err := io.ReadAll(io.LimitReader(f, maxFile+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxFile {
+		return nil, fmt.Errorf("authz %s: larger than %d bytes", path, maxFile)
+	}
+
+	r, err := Parse(data, path)
+	if err != nil {
+		return nil, fmt.Errorf("authz %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// 규칙 파싱 + 검증(JSON은 YAML의 부분집합)
+func Parse(data []byte, source string) (*Rules, error) {
+	// 모르는 키는 오타로 보고 거절
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	var f File
+	if err := dec.Decode(&f); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("empty authz file")
+		}
+		return nil, err
+	}
+	return compile(f, source)
+}
+
+// 검증 + 사용자별 허용 범위 계산 + 버전 해시
+func compile(f File, source string) (*Rules, error) {
+	if len(f.Roles) == 0 {
+		return nil, errors.New("no roles")
+	}
+	if len(f.Users) == 0 {
+		return nil, errors.New("no users")
+	}
+
+	// 역할 검증
+	for name, r := range f.Roles {
+		if strings.TrimSpace(name) == "" {
+			return nil, errors.New("empty role name")
+		}
+		for _, t := range r.Types {
+			if t != Any && !checkedTypes[t] {
+				return nil, fmt.Errorf("role %q: unknown type %q", name, t)
+			}
+		}
+		for _, c := range r.Commands {
+			if strings.TrimSpace(c) == "" || strings.ContainsAny(c, "/ \t") {
+				return nil, fmt.Errorf("role %q: invalid command %q", name, c)
+			}
+		}
+		for _, root := range r.FileRoots {
+			if strings.TrimSpace(root) == "" {
+				return nil, fmt.Errorf("role %q: empty file root", name)
+			}
+			// 설정에 없는 루트 이름은 오타로 봄
+			if rn, _ := filex.SplitRoot(strings.TrimSpace(root)); rn != "" && filex.Lookup(rn) == nil {
+				return nil, fmt.Errorf("role %q: unknown file root %q", name, rn)
+			}
+		}
+	}
+
+	// 사용자별 역할 합집합
+	users := make(map[string]*grant, len(f.Users))
+	for user, roles := range f.Users {
+		if strings.TrimSpace(user) == "" {
+			return nil, errors.New("empty user id")
+		}
+		g := &grant{roles: roles, types: map[string]bool{}, commands: map[string]bool{}}
+		for _, name := range roles {
+			r, ok := f.Roles[name]
+			if !ok {
+				return nil, fmt.Errorf("user %q: unknown role %q", user, name)
+			}
+			for _, t := range r.Types {
+				g.types[t] = true
+			}
+			for _, c := range r.Commands {
+				g.commands[c] = true
+			}
+			for _, root := range r.FileRoots {
+				g.roots = append(g.roots, cleanPath(root))
+			}
+		}
+		users[user] = g
+	}
+
+	// 내용 해시(맵 키는 정렬되어 직렬화됨)
+	b, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(b)
+
+	return &Rules{
+		Version:  "sha256:" + hex.EncodeToString(sum[:]),
+		Source:   source,
+		LoadedAt: time.Now(),
+		users:    users,
+	}, nil
+}
+
+// 루트 기준 정규 경로("a/../b" → "/b", "logs:" → "logs:/")
+func cleanPath(p string) string {
+	p = strings.TrimSpace(p)
+	if p == Any {
+		return Any
+	}
+	return filex.Canonical(p)
+}
+
+// 사용자 허용 범위(목록에 없으면 "*" 항목)
+func (r *Rules) grantOf(user string) *grant {
+	if g, ok := r.users[user]; ok {
+		return g
+	}
+	if g, ok := r.users[Any]; ok {
+		return g
+	}
+	return &grant{}
+}
+
+// 사용자 목록(로그용)
+func (r *Rules) Users() []string {
+	out := make([]string, 0, len(r.users))
+	for u := range r.users {
+		out = append(out, u)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// 요청 타입 허용 여부
+func (r *Rules) CheckType(user, reqType string) error {
+	if r == nil || !checkedTypes[reqType] {
+		return nil
+	}
+	g := r.grantOf(user)
+	if g.types[Any] || g.types[reqType] {
+		return nil
+	}
+	return &Denied{User: user, What: "type " + reqType, Roles: g.roles}
+}
+
+// 명령 + 경로 인자 허용 여부
+func (r *Rules) CheckCmd(user, name string, paths []string) error {
+	if r == nil {
+		return nil
+	}
+	g := r.grantOf(user)
+	if !g.commands[Any] && !g.commands[name] {
+		return &Denied{User: user, What: "command " + name, Roles: g.roles}
+	}
+	for _, p := range paths {
+		if err := r.CheckPath(user, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 경로 허용 여부(루트 접두사 포함)
+// - 요청 경로와 심볼릭 링크를 따라간 실제 경로가 모두 허용 범위여야 함
+func (r *Rules) CheckPath(user, p string) error {
+	if r == nil {
+		return nil
+	}
+	g := r.grantOf(user)
+	clean := cleanPath(p)
+	if !g.allows(clean) {
+		return &Denied{User: user, What: "path " + clean, Roles: g.roles}
+	}
+	// 허용된 경로 안의 링크가 다른 경로를 가리키면 거절
+	if real := filex.Resolved(p); real != "" && !g.allows(real) {
+		return &Denied{User: user, What: "path " + clean + " -> " + real, Roles: g.roles}
+	}
+	return nil
+}
+
+// 정규 경로가 허용 경로 하위인지
+func (g *grant) allows(clean string) bool {
+	for _, root := range g.roots {
+		if root == Any || clean == root || strings.HasPrefix(clean, strings.TrimSuffix(root, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package authz
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang-network-labs/tcp/internal/filex"
+)
+
+// 허용 경로 안의 링크가 허용 밖 경로를 가리키면 거절
+func TestCheckPathSymlink(t *testing.T) {
+	dir := t.TempDir()
+	for _, d := range []string{"pub", "secret"} {
+		if err := os.Mkdir(filepath.Join(dir, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("../secret", filepath.Join(dir, "pub", "leak")); err != nil {
+		t.Skipf("symlink: %v", err)
+	}
+	if err := os.Symlink(".", filepath.Join(dir, "pub", "self")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := filex.Configure("data=" + dir + ":ro"); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := Parse([]byte("roles:\n  r:\n    types: [file]\n    file_roots: [\"/pub\"]\nusers:\n  u: [r]\n"), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		path string
+		ok   bool
+	}{
+		{"/pub", true},
+		{"/pub/new/file.txt", true},
+		{"/pub/self/x", true},
+		{"/secret/a", false},
+		{"/pub/leak", false},
+		{"/pub/leak/a", false},
+		{"/pub/leak/../leak/a", false},
+		{"data:/pub/leak/new", false},
+	}
+	for _, tc := range cases {
+		err := rules.CheckPath("u", tc.path)
+		if tc.ok && err != nil {
+			t.Errorf("%s: %v", tc.path, err)
+		}
+		if !tc.ok && !errors.Is(err, ErrForbidden) {
+			t.Errorf("%s: err = %v, want forbidden", tc.path, err)
+		}
+	}
+}
@@ -1,30 +1,40 @@
 package execx
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"os/exec"
-	"runtime"
 	"strings"
+	"time"
 
 	"golang-network-labs/tcp/internal/protocol"
 )
 
-// 허용 명령
-var allowCmd = map[string]bool{
-	"uname":  true,
-	"date":   true,
-	"whoami": true,
-	"id":     true,
-	"ls":     true,
-	"pwd":    true,
-}
+// 자식 종료 후 파이프 정리 대기
+const waitDelay = 1 * time.Second
+
+// 클라이언트 cancel 요청(context cause로 전달)
+var ErrCancelled = errors.New("cancelled by client")
 
 // cmd 실행 처리
-func Run(req protocol.Req, base protocol.Res) protocol.Res {
+func Run(ctx context.Context, req protocol.Req, base protocol.Res, opt Options) protocol.Res {
+	return RunStream(ctx, req, base, opt, nil)
+}
+
+// cmd 실행 처리(emit이 있으면 출력 조각을 실행 중에 전달)
+func RunStream(ctx context.Context, req protocol.Req, base protocol.Res, opt Options, emit EmitFunc) protocol.Res {
+	// 실행 전 거절은 종료 코드 없음(-1)
+	notStarted := -1
+	base.ExitCode = &notStarted
+
 	// cmd 공백 제거
 	cmdText := strings.TrimSpace(req.Cmd)
 	if cmdText == "" {
 		base.Ok = false
 		base.Error = "cmd required"
+		base.Code = protocol.CodeBadRequest
 		return base
 	}
 
@@ -33,39 +43,134 @@ func Run(req protocol.Req, base protocol.Res) protocol.Res {
 	if len(tokens) == 0 {
 		base.Ok = false
 		base.Error = "cmd required"
+		base.Code = protocol.CodeBadRequest
 		return base
 	}
 
 	// allowlist 검사
 	mainCmd := tokens[0]
-	if !allowCmd[mainCmd] {
+	policy, ok := CurrentPolicy().lookup(mainCmd)
+	if !ok {
+		base.Ok = false
+		base.Error = errNotAllowed.Error()
+		base.Code = protocol.CodeCmdNotAllowed
+		return base
+	}
+
+	// 인자 정책 검사
+	args, err := policy.check(tokens[1:])
+	if err != nil {
 		base.Ok = false
-		base.Error = "command not allowed"
+		base.Error = err.Error()
+		base.Code = policyCode(err)
 		return base
 	}
 
-	// OS별 실행 분기
-	var out []byte
-	var err error
+	// 미설정 제한은 기본값
+	opt = opt.withDefaults()
+
+	// 실행 슬롯 확보(검사를 통과한 요청만 대기열에 들어감)
+	if opt.Pool != nil {
+		t, release, err := opt.Pool.Acquire(ctx)
+		base.QueueDepth = t.Depth
+		base.QueueWaitMs = t.Wait.Milliseconds()
+		if err != nil {
+			return Rejected(ctx, base, err)
+		}
+		defer release()
+	}
+
+	// 요청별 제한시간
+	timeout := opt.timeout(req.TimeoutMs)
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// 셸 없이 argv로 직접 실행
+	cmd := exec.CommandContext(runCtx, mainCmd, args...)
+	// 경로 인자 명령은 루트에서 실행
+	if policy.PathArgs {
+		cmd.Dir = cmdRoot
+	}
+
+	// 손자 프로세스까지 그룹으로 종료
+	setProcGroup(cmd)
+	cmd.Cancel = func() error { return killProcGroup(cmd) }
+	cmd.WaitDelay = waitDelay
+
+	// 출력 상한 버퍼(stdout, stderr, 합친 출력)
+	stdout := &capBuffer{max: opt.MaxOutput}
+	stderr := &capBuffer{max: opt.MaxOutput}
+	combined := &capBuffer{max: opt.MaxOutput}
+	var outW, errW io.Writer = stdout, stderr
+	if emit != nil {
+		outW = streamWriter{kind: protocol.EventStdout, buf: stdout, emit: emit}
+		errW = streamWriter{kind: protocol.EventStderr, buf: stderr, emit: emit}
+	}
+	cmd.Stdout = io.MultiWriter(outW, combined)
+	cmd.Stderr = io.MultiWriter(errW, combined)
+
+	// 실행 + 시간 측정
+	start := time.Now()
+	err = cmd.Run()
+	base.StartedAt = start
+	base.DurationMs = time.Since(start).Milliseconds()
+
+	base.Output = combined.String()
+	base.Stdout = stdout.String()
+	base.Stderr = stderr.String()
+	base.Truncated = stdout.Truncated() || stderr.Truncated() || combined.Truncated()
+
+	// 종료 코드(시그널 종료/미실행은 -1)
+	if cmd.ProcessState != nil {
+		code := cmd.ProcessState.ExitCode()
+		base.ExitCode = &code
+	}
+
+	// 제한시간 초과
+	if errors.Is(runCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		base.Ok = false
+		base.Reason = protocol.ReasonTimeout
+		base.Code = protocol.CodeTimeout
+		base.Error = fmt.Sprintf("timeout after %s", timeout)
+		return base
+	}
 
-	// 윈도우면 cmd
-	if runtime.GOOS == "windows" {
-		out, err = exec.Command("cmd", "/C", cmdText).CombinedOutput()
-	} else {
-		// 리눅스면 sh
-		out, err = exec.Command("sh", "-c", cmdText).CombinedOutput()
+	// 클라이언트 취소(프로세스 그룹은 이미 kill됨)
+	if err != nil && cancelled(ctx) {
+		base.Ok = false
+		base.Reason = protocol.ReasonCancelled
+		base.Code = protocol.CodeCancelled
+		base.Error = ErrCancelled.Error()
+		return base
 	}
 
 	// 실패 처리
 	if err != nil {
 		base.Ok = false
-		base.Output = string(out)
 		base.Error = err.Error()
+		// 시작 실패 등
+		base.Code = protocol.CodeInternal
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			// 시그널 종료는 -1
+			if exitErr.ExitCode() < 0 {
+				base.Reason = protocol.ReasonKilled
+				base.Code = protocol.CodeKilled
+			} else {
+				base.Reason = protocol.ReasonExit
+				base.Code = protocol.CodeExitNonZero
+			}
+		}
 		return base
 	}
 
 	// 성공 처리
 	base.Ok = true
-	base.Output = string(out)
 	return base
 }
+
+// cancel 요청으로 끝난 context인지
+func cancelled(ctx context.Context) bool {
+	return ctx.Err() != nil && errors.Is(context.Cause(ctx), ErrCancelled)
+}
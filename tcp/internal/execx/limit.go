@@ -0,0 +1,127 @@
+package execx
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// 실행 제한 설정
+type Options struct {
+	// 요청에 timeout이 없을 때 기본값
+	DefaultTimeout time.Duration
+	// 요청 timeout 상한
+	MaxTimeout time.Duration
+	// 출력 최대 바이트
+	MaxOutput int
+
+	// 서버 전체 동시 실행 수
+	Workers int
+	// 실행 대기열 크기(음수면 대기 없이 바로 거절)
+	QueueSize int
+	// 대기열 최대 대기 시간
+	QueueTimeout time.Duration
+	// 실행 슬롯(nil이면 제한 없음)
+	Pool *Pool
+}
+
+// 기본 제한값
+var DefaultOptions = Options{
+	DefaultTimeout: 10 * time.Second,
+	MaxTimeout:     60 * time.Second,
+	MaxOutput:      1 << 20,
+}
+
+// 0 값은 기본값으로 채움
+func (o Options) withDefaults() Options {
+	if o.DefaultTimeout <= 0 {
+		o.DefaultTimeout = DefaultOptions.DefaultTimeout
+	}
+	if o.MaxTimeout <= 0 {
+		o.MaxTimeout = DefaultOptions.MaxTimeout
+	}
+	if o.MaxOutput <= 0 {
+		o.MaxOutput = DefaultOptions.MaxOutput
+	}
+	return o
+}
+
+// 요청 timeout → 실제 적용값
+func (o Options) timeout(reqMs int64) time.Duration {
+	d := o.DefaultTimeout
+	if reqMs > 0 {
+		d = time.Duration(reqMs) * time.Millisecond
+	}
+	// 상한 적용
+	if d > o.MaxTimeout {
+		d = o.MaxTimeout
+	}
+	return d
+}
+
+// 상한까지만 보관하는 버퍼
+type capBuffer struct {
+	// stdout/stderr 고루틴이 함께 씀
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+// 넘치는 출력은 버리되 자식 파이프는 막지 않음
+func (c *capBuffer) Write(p []byte) (int, error) {
+	c.accept(p)
+	return len(p), nil
+}
+
+// 상한 안에 들어간 부분만 보관 후 반환
+func (c *capBuffer) accept(p []byte) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	room := c.max - c.buf.Len()
+	if room <= 0 {
+		if len(p) > 0 {
+			c.truncated = true
+		}
+		return nil
+	}
+	if len(p) > room {
+		p = p[:room]
+		c.truncated = true
+	}
+	c.buf.Write(p)
+	return p
+}
+
+// 보관된 출력
+func (c *capBuffer) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.String()
+}
+
+// 잘림 여부
+func (c *capBuffer) Truncated() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.truncated
+}
+
+// 출력 조각 전달 콜백(고루틴 안전해야 함)
+type EmitFunc func(kind string, data []byte)
+
+// 보관과 동시에 조각을 흘려보내는 writer
+type streamWriter struct {
+	kind string
+	buf  *capBuffer
+	emit EmitFunc
+}
+
+// 상한 안의 출력만 전달
+func (w streamWriter) Write(p []byte) (int, error) {
+	if acc := w.buf.accept(p); len(acc) > 0 {
+		w.emit(w.kind, acc)
+	}
+	return len(p), nil
+}
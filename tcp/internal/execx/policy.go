@@ -0,0 +1,188 @@
+package execx
+
+import (
+	"errors"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang-network-labs/tcp/internal/protocol"
+)
+
+// 경로 인자 루트(작업 디렉터리)
+var cmdRoot = "/data"
+
+// 경로 인자 루트 변경(기동 시 기본 파일 루트로)
+func SetRoot(dir string) { cmdRoot = filepath.Clean(dir) }
+
+// 명령별 인자 정책(검사용으로 컴파일된 형태)
+type Policy struct {
+	// 허용 플래그("-l", "--all" 형태)
+	Flags []string
+	// 최대 인자 수(플래그 포함)
+	MaxArgs int
+	// 일반 인자 허용 패턴(nil이면 일반 인자 금지)
+	ArgPattern *regexp.Regexp
+	// 일반 인자를 루트 하위 경로로 강제
+	PathArgs bool
+}
+
+// 내장 허용 명령 + 정책(POLICY_FILE이 없을 때)
+var builtinCommands = map[string]protocol.CommandPolicy{
+	"uname": {
+		Flags:   []string{"-a", "-s", "-n", "-r", "-v", "-m", "-p", "-i", "-o"},
+		MaxArgs: 4,
+	},
+	"date": {
+		Flags:      []string{"-u", "-R", "-I"},
+		MaxArgs:    2,
+		ArgPattern: `^\+[%A-Za-z0-9:._/-]+$`,
+	},
+	"whoami": {},
+	"id": {
+		Flags:      []string{"-u", "-g", "-G", "-n", "-r"},
+		MaxArgs:    4,
+		ArgPattern: `^[a-z_][a-z0-9_-]{0,31}$`,
+	},
+	"ls": {
+		Flags:      []string{"-l", "-a", "-A", "-h", "-1", "-t", "-S", "-r", "-R", "-F"},
+		MaxArgs:    8,
+		ArgPattern: `^[A-Za-z0-9._/-]+$`,
+		PathArgs:   true,
+	},
+	"pwd": {
+		Flags:   []string{"-L", "-P"},
+		MaxArgs: 1,
+	},
+}
+
+// 정책 위반 에러
+var (
+	errNotAllowed  = errors.New("command not allowed")
+	errFlag        = errors.New("flag not allowed")
+	errTooManyArgs = errors.New("too many arguments")
+	errArg         = errors.New("argument not allowed")
+	errPath        = errors.New("invalid path")
+)
+
+// 정책 위반 → 에러 코드
+func policyCode(err error) string {
+	switch {
+	case errors.Is(err, errNotAllowed):
+		return protocol.CodeCmdNotAllowed
+	case errors.Is(err, errPath):
+		return protocol.CodePathEscape
+	default:
+		return protocol.CodeArgNotAllowed
+	}
+}
+
+// 명령 이름 + 경로 인자(권한 검사용)
+// - 경로 인자가 없으면 작업 디렉터리(루트)를 대상으로 봄
+func CmdTargets(cmdText string) (name string, paths []string) {
+	tokens := strings.Fields(cmdText)
+	if len(tokens) == 0 {
+		return "", nil
+	}
+	name = tokens[0]
+
+	p, ok := CurrentPolicy().lookup(name)
+	if !ok || !p.PathArgs {
+		return name, nil
+	}
+	for _, a := range tokens[1:] {
+		if !strings.HasPrefix(a, "-") || a == "-" {
+			paths = append(paths, a)
+		}
+	}
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	return name, paths
+}
+
+// 인자 검사 + 경로 인자 루트 하위로 변환
+func (p Policy) check(args []string) ([]string, error) {
+	// 인자 수 제한
+	if len(args) > p.MaxArgs {
+		return nil, errTooManyArgs
+	}
+
+	// 허용 플래그 집합
+	flags := make(map[string]bool, len(p.Flags))
+	for _, f := range p.Flags {
+		flags[f] = true
+	}
+
+	out := make([]string, 0, len(args))
+	for _, a := range args {
+		// 플래그 검사
+		if strings.HasPrefix(a, "-") && a != "-" {
+			if !flagAllowed(a, flags) {
+				return nil, errFlag
+			}
+			out = append(out, a)
+			continue
+		}
+
+		// 일반 인자 패턴 검사
+		if p.ArgPattern == nil || !p.ArgPattern.MatchString(a) {
+			return nil, errArg
+		}
+
+		// 경로 인자면 루트 하위로 강제
+		if p.PathArgs {
+			abs, err := underRoot(cmdRoot, a)
+			if err != nil {
+				return nil, err
+			}
+			a = abs
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// 플래그 허용 여부("-la"는 "-l", "-a"로 분해)
+func flagAllowed(a string, flags map[string]bool) bool {
+	// "--"(옵션 종료) 금지
+	if a == "--" {
+		return false
+	}
+	// 긴 플래그는 그대로 비교
+	if strings.HasPrefix(a, "--") {
+		return flags[a]
+	}
+	// 짧은 플래그 묶음 분해
+	for _, c := range a[1:] {
+		if !flags["-"+string(c)] {
+			return false
+		}
+	}
+	return true
+}
+
+// 루트 하위 절대경로로 변환
+func underRoot(root, p string) (string, error) {
+	// 루트 기준 정규화
+	abs := filepath.Join(root, filepath.Clean("/"+p))
+
+	// 루트 자신 또는 하위만 허용
+	if !within(root, abs) {
+		return "", errPath
+	}
+
+	// 심볼릭 링크를 따라간 실제 경로도 루트 안이어야 함(없는 경로는 ls가 처리)
+	if real, err := filepath.EvalSymlinks(abs); err == nil {
+		base, err := filepath.EvalSymlinks(root)
+		if err != nil || !within(base, real) {
+			return "", errPath
+		}
+	}
+	return abs, nil
+}
+
+// root 자신 또는 하위 경로인지
+func within(root, p string) bool {
+	return p == root || strings.HasPrefix(p, strings.TrimSuffix(root, string(filepath.Separator))+string(filepath.Separator))
+}
@@ -0,0 +1,232 @@
+package execx
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"golang-network-labs/tcp/internal/protocol"
+)
+
+// 테스트용 ls 유사 정책
+func testPolicy() Policy {
+	return Policy{
+		Flags:      []string{"-l", "-a", "-h", "--all", "--color"},
+		MaxArgs:    4,
+		ArgPattern: regexp.MustCompile(`^[A-Za-z0-9._/-]+$`),
+	}
+}
+
+func TestFlagAllowed(t *testing.T) {
+	flags := map[string]bool{"-l": true, "-a": true, "--all": true, "--color": true}
+
+	cases := []struct {
+		flag string
+		want bool
+	}{
+		{"-l", true},
+		{"-a", true},
+		{"-la", true},
+		{"-al", true},
+		{"-x", false},
+		{"-lx", false},
+		{"--all", true},
+		{"--color", true},
+		{"--al", false},
+		{"--allx", false},
+		// --flag=value 형태는 값까지 정확히 일치해야 하며 정책 플래그에는 '='가 없음
+		{"--color=always", false},
+		{"--all=", false},
+		{"--=all", false},
+		// 짧은 플래그 값 붙이기도 분해 후 거절
+		{"-l=a", false},
+		// 옵션 종료 표시 금지
+		{"--", false},
+	}
+	for _, tc := range cases {
+		if got := flagAllowed(tc.flag, flags); got != tc.want {
+			t.Errorf("flagAllowed(%q) = %v, want %v", tc.flag, got, tc.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	cases := []struct {
+		name    string
+		args    []string
+		wantErr error
+	}{
+		{"no args", nil, nil},
+		{"allowed flags", []string{"-l", "-a"}, nil},
+		{"bundled flags", []string{"-lah"}, nil},
+		{"long flag", []string{"--all"}, nil},
+		{"plain arg", []string{"dir/file.txt"}, nil},
+		{"lone dash is arg", []string{"-"}, nil},
+		{"denied flag", []string{"-R"}, errFlag},
+		{"denied in bundle", []string{"-lR"}, errFlag},
+		{"long flag with value", []string{"--color=always"}, errFlag},
+		{"end of options", []string{"--", "-l"}, errFlag},
+		{"too many args", []string{"a", "b", "c", "d", "e"}, errTooManyArgs},
+		{"shell meta", []string{"a;id"}, errArg},
+		{"glob", []string{"*"}, errArg},
+		{"empty arg", []string{""}, errArg},
+	}
+	p := testPolicy()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := p.check(tc.args)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("check(%q) err = %v, want %v", tc.args, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckNoArgPattern(t *testing.T) {
+	// 패턴 없는 명령은 일반 인자 전부 거절
+	p := Policy{Flags: []string{"-a"}, MaxArgs: 2}
+	if _, err := p.check([]string{"-a"}); err != nil {
+		t.Fatalf("flag only: %v", err)
+	}
+	if _, err := p.check([]string{"x"}); !errors.Is(err, errArg) {
+		t.Fatalf("plain arg err = %v, want %v", err, errArg)
+	}
+}
+
+func TestUnderRoot(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	// 루트 안 디렉터리/파일
+	if err := os.MkdirAll(filepath.Join(root, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// 루트 밖을 가리키는 링크와 루트 안을 가리키는 링크
+	mustSymlink(t, outside, filepath.Join(root, "escape"))
+	mustSymlink(t, filepath.Join(outside, "secret"), filepath.Join(root, "secret"))
+	mustSymlink(t, filepath.Join(root, "sub"), filepath.Join(root, "inner"))
+	mustSymlink(t, "../..", filepath.Join(root, "sub", "up"))
+
+	cases := []struct {
+		name    string
+		arg     string
+		want    string
+		wantErr error
+	}{
+		{"root dot", ".", root, nil},
+		{"child", "sub", filepath.Join(root, "sub"), nil},
+		{"absolute is rooted", "/sub", filepath.Join(root, "sub"), nil},
+		{"missing is rooted", "nope/x", filepath.Join(root, "nope", "x"), nil},
+		// ".."는 루트 기준으로 정규화되어 밖으로 못 나감
+		{"dotdot clamped", "../../etc", filepath.Join(root, "etc"), nil},
+		{"dotdot inside", "sub/../sub", filepath.Join(root, "sub"), nil},
+		{"absolute dotdot", "/../..", root, nil},
+		{"inner symlink", "inner", filepath.Join(root, "inner"), nil},
+		// 실제 경로가 루트 밖이면 거절
+		{"dir symlink escape", "escape", "", errPath},
+		{"through symlink escape", "escape/secret", "", errPath},
+		{"file symlink escape", "secret", "", errPath},
+		{"relative symlink escape", "sub/up", "", errPath},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := underRoot(root, tc.arg)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("underRoot(%q) err = %v, want %v", tc.arg, err, tc.wantErr)
+			}
+			if err == nil && got != tc.want {
+				t.Fatalf("underRoot(%q) = %q, want %q", tc.arg, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCheckPathArgs(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	mustSymlink(t, outside, filepath.Join(root, "escape"))
+
+	// 경로 인자 루트 교체(테스트 후 복원)
+	prev := cmdRoot
+	SetRoot(root)
+	t.Cleanup(func() { cmdRoot = prev })
+
+	p := testPolicy()
+	p.PathArgs = true
+
+	args, err := p.check([]string{"-l", "../x"})
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if want := []string{"-l", filepath.Join(root, "x")}; args[0] != want[0] || args[1] != want[1] {
+		t.Fatalf("check args = %q, want %q", args, want)
+	}
+
+	if _, err := p.check([]string{"escape"}); !errors.Is(err, errPath) {
+		t.Fatalf("symlink escape err = %v, want %v", err, errPath)
+	}
+	if code := policyCode(errPath); code != protocol.CodePathEscape {
+		t.Fatalf("policyCode(errPath) = %q, want %q", code, protocol.CodePathEscape)
+	}
+}
+
+func TestWithin(t *testing.T) {
+	cases := []struct {
+		root, p string
+		want    bool
+	}{
+		{"/data", "/data", true},
+		{"/data", "/data/a", true},
+		{"/data/", "/data/a", true},
+		{"/data", "/database", false},
+		{"/data", "/", false},
+		{"/data", "/etc/data", false},
+	}
+	for _, tc := range cases {
+		if got := within(tc.root, tc.p); got != tc.want {
+			t.Errorf("within(%q, %q) = %v, want %v", tc.root, tc.p, got, tc.want)
+		}
+	}
+}
+
+func TestRunDisallowed(t *testing.T) {
+	cases := []struct {
+		name string
+		cmd  string
+		code string
+	}{
+		{"unknown", "rm -rf /", protocol.CodeCmdNotAllowed},
+		{"shell", "sh -c id", protocol.CodeCmdNotAllowed},
+		{"absolute path", "/bin/ls", protocol.CodeCmdNotAllowed},
+		{"relative path", "./ls", protocol.CodeCmdNotAllowed},
+		{"chained", "ls;id", protocol.CodeCmdNotAllowed},
+		{"case differs", "LS", protocol.CodeCmdNotAllowed},
+		{"denied flag", "ls -d", protocol.CodeArgNotAllowed},
+		{"arg on no-arg cmd", "whoami root", protocol.CodeArgNotAllowed},
+		{"empty", "   ", protocol.CodeBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := Run(context.Background(), protocol.Req{Cmd: tc.cmd}, protocol.Res{}, Options{})
+			if res.Ok || res.Code != tc.code {
+				t.Fatalf("Run(%q) = ok %v code %q, want code %q", tc.cmd, res.Ok, res.Code, tc.code)
+			}
+			if res.ExitCode == nil || *res.ExitCode != -1 {
+				t.Fatalf("Run(%q) exit = %v, want -1 (not started)", tc.cmd, res.ExitCode)
+			}
+		})
+	}
+}
+
+func mustSymlink(t *testing.T, target, link string) {
+	t.Helper()
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlink: %v", err)
+	}
+}
@@ -0,0 +1,220 @@
+package execx
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"golang-network-labs/tcp/internal/protocol"
+)
+
+// 정책 파일 제한
+const (
+	// 명령당 최대 인자 수 상한
+	maxPolicyArgs = 64
+	// 정책 파일 최대 크기
+	maxPolicyFile = 1 << 20
+)
+
+// 명령 이름(경로/공백 금지 → PATH에서만 찾음)
+var cmdNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// 플래그 형태("-l", "--all")
+var flagPattern = regexp.MustCompile(`^(-[A-Za-z0-9]|--[A-Za-z0-9][A-Za-z0-9-]*)$`)
+
+// 정책 파일 스키마(YAML/JSON 공통)
+type PolicyFile struct {
+	// 명령 → 인자 정책
+	Commands map[string]protocol.CommandPolicy `json:"commands" yaml:"commands"`
+}
+
+// 검증/컴파일이 끝난 정책 묶음(교체만 하고 수정하지 않음)
+type PolicySet struct {
+	// 정책 내용 해시("sha256:...")
+	Version string
+	// 출처(파일 경로 또는 "builtin")
+	Source string
+	// 적용 시각
+	LoadedAt time.Time
+
+	// 원본(정책 응답용)
+	spec map[string]protocol.CommandPolicy
+	// 검사용
+	cmds map[string]Policy
+}
+
+// 적용중인 정책(요청마다 한 번 읽어 끝까지 사용)
+var active atomic.Pointer[PolicySet]
+
+func init() {
+	ps, err := compilePolicy(PolicyFile{Commands: builtinCommands}, "builtin")
+	if err != nil {
+		panic("execx: builtin policy: " + err.Error())
+	}
+	active.Store(ps)
+}
+
+// 적용중인 정책
+func CurrentPolicy() *PolicySet { return active.Load() }
+
+// 정책 교체(이미 실행중인 요청은 이전 정책 그대로)
+func SetPolicy(ps *PolicySet) { active.Store(ps) }
+
+// 파일에서 정책 읽기 + 검증(확장자와 무관하게 YAML/JSON 모두 허용)
+func LoadPolicy(path string) (*PolicySet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// 크기 제한
+	data, err := io.ReadAll(io.LimitReader(f, maxPolicyFile+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxPolicyFile {
+		return nil, fmt.Errorf("policy %s: larger than %d bytes", path, maxPolicyFile)
+	}
+
+	ps, err := ParsePolicy(data, path)
+	if err != nil {
+		return nil, fmt.Errorf("policy %s: %w", path, err)
+	}
+	return ps, nil
+}
+
+// 정책 파싱 + 검증(JSON은 YAML의 부분집합)
+func ParsePolicy(data []byte, source string) (*PolicySet, error) {
+	// 모르는 키는 오타로 보고 거절
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	var pf PolicyFile
+	if err := dec.Decode(&pf); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("empty policy")
+		}
+		return nil, err
+	}
+	return compilePolicy(pf, source)
+}
+
+// 검증 + 정규식 컴파일 + 버전 해시
+func compilePolicy(pf PolicyFile, source string) (*PolicySet, error) {
+	if len(pf.Commands) == 0 {
+		return nil, errors.New("no commands")
+	}
+
+	spec := make(map[string]protocol.CommandPolicy, len(pf.Commands))
+	cmds := make(map[string]Policy, len(pf.Commands))
+	for name, cp := range pf.Commands {
+		p, err := compileCommand(name, cp)
+		if err != nil {
+			return nil, fmt.Errorf("command %q: %w", name, err)
+		}
+		// 플래그 순서는 의미 없음(해시 안정화)
+		cp.Flags = append([]string(nil), cp.Flags...)
+		sort.Strings(cp.Flags)
+		spec[name] = cp
+		cmds[name] = p
+	}
+
+	// 내용 해시(맵 키는 정렬되어 직렬화됨, 주석/서식 변경은 무시)
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(b)
+
+	return &PolicySet{
+		Version:  "sha256:" + hex.EncodeToString(sum[:]),
+		Source:   source,
+		LoadedAt: time.Now(),
+		spec:     spec,
+		cmds:     cmds,
+	}, nil
+}
+
+// 명령 하나 검증
+func compileCommand(name string, cp protocol.CommandPolicy) (Policy, error) {
+	// 경로 실행/셸 문자 차단
+	if !cmdNamePattern.MatchString(name) {
+		return Policy{}, errors.New("invalid command name")
+	}
+	if cp.MaxArgs < 0 || cp.MaxArgs > maxPolicyArgs {
+		return Policy{}, fmt.Errorf("max_args must be 0..%d", maxPolicyArgs)
+	}
+	for _, f := range cp.Flags {
+		if !flagPattern.MatchString(f) {
+			return Policy{}, fmt.Errorf("invalid flag %q", f)
+		}
+	}
+
+	p := Policy{Flags: cp.Flags, MaxArgs: cp.MaxArgs, PathArgs: cp.PathArgs}
+	if cp.ArgPattern != "" {
+		// 부분 일치로 새는 인자 방지
+		if !strings.HasPrefix(cp.ArgPattern, "^") || !strings.HasSuffix(cp.ArgPattern, "$") {
+			return Policy{}, errors.New("arg_pattern must be anchored with ^ and $")
+		}
+		re, err := regexp.Compile(cp.ArgPattern)
+		if err != nil {
+			return Policy{}, fmt.Errorf("arg_pattern: %w", err)
+		}
+		p.ArgPattern = re
+	}
+	if cp.PathArgs && p.ArgPattern == nil {
+		return Policy{}, errors.New("path_args requires arg_pattern")
+	}
+	return p, nil
+}
+
+// 명령 정책 조회
+func (ps *PolicySet) lookup(name string) (Policy, bool) {
+	p, ok := ps.cmds[name]
+	return p, ok
+}
+
+// 허용 명령 이름(정렬)
+func (ps *PolicySet) Commands() []string {
+	names := make([]string, 0, len(ps.cmds))
+	for name := range ps.cmds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PATH에서 찾을 수 없는 명령(경고용)
+func (ps *PolicySet) Missing() []string {
+	var out []string
+	for _, name := range ps.Commands() {
+		if _, err := exec.LookPath(name); err != nil {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// policy 응답 본문
+func (ps *PolicySet) Info() *protocol.PolicyInfo {
+	return &protocol.PolicyInfo{
+		Version:  ps.Version,
+		Source:   ps.Source,
+		LoadedAt: ps.LoadedAt,
+		Commands: ps.spec,
+	}
+}
@@ -0,0 +1,142 @@
+package execx
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"golang-network-labs/tcp/internal/protocol"
+)
+
+// 실행 풀 기본값
+const (
+	defaultWorkers      = 8
+	defaultQueueSize    = 64
+	defaultQueueTimeout = 10 * time.Second
+)
+
+// 대기열 거절 사유
+var (
+	ErrQueueFull    = errors.New("execution queue full")
+	ErrQueueTimeout = errors.New("timed out waiting for an execution slot")
+)
+
+// 동시 실행 수 + 대기열 상한(연결/클라이언트와 무관하게 서버 전체 공유)
+type Pool struct {
+	// 실행 슬롯
+	slots chan struct{}
+	// 대기 상한/시간
+	maxQueue int
+	maxWait  time.Duration
+
+	// 현재 대기 수
+	mu     sync.Mutex
+	queued int
+}
+
+// 슬롯 획득 결과
+type Ticket struct {
+	// 들어갈 때 앞에 있던 대기 수(바로 실행이면 0)
+	Depth int
+	// 실행까지 기다린 시간
+	Wait time.Duration
+}
+
+// 실행 풀 생성(0 값은 기본값)
+func NewPool(opt Options) *Pool {
+	if opt.Workers <= 0 {
+		opt.Workers = defaultWorkers
+	}
+	if opt.QueueSize < 0 {
+		opt.QueueSize = 0
+	} else if opt.QueueSize == 0 {
+		opt.QueueSize = defaultQueueSize
+	}
+	if opt.QueueTimeout <= 0 {
+		opt.QueueTimeout = defaultQueueTimeout
+	}
+	return &Pool{
+		slots:    make(chan struct{}, opt.Workers),
+		maxQueue: opt.QueueSize,
+		maxWait:  opt.QueueTimeout,
+	}
+}
+
+// 슬롯 대기 상한(hello로 클라이언트에 알림)
+func (p *Pool) QueueTimeout() time.Duration {
+	return p.maxWait
+}
+
+// 실행 슬롯 획득(release는 반드시 호출)
+// - 빈 슬롯이 없고 대기열이 가득 차면 ErrQueueFull
+// - 대기 시간이 상한을 넘으면 ErrQueueTimeout
+func (p *Pool) Acquire(ctx context.Context) (Ticket, func(), error) {
+	release := func() { <-p.slots }
+
+	// 빈 슬롯이면 바로 실행
+	select {
+	case p.slots <- struct{}{}:
+		return Ticket{}, release, nil
+	default:
+	}
+
+	// 대기열 등록
+	p.mu.Lock()
+	if p.queued >= p.maxQueue {
+		depth := p.queued
+		p.mu.Unlock()
+		return Ticket{Depth: depth}, nil, ErrQueueFull
+	}
+	depth := p.queued
+	p.queued++
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		p.queued--
+		p.mu.Unlock()
+	}()
+
+	// 슬롯 대기
+	start := time.Now()
+	timer := time.NewTimer(p.maxWait)
+	defer timer.Stop()
+
+	select {
+	case p.slots <- struct{}{}:
+		return Ticket{Depth: depth, Wait: time.Since(start)}, release, nil
+	case <-timer.C:
+		return Ticket{Depth: depth, Wait: time.Since(start)}, nil, ErrQueueTimeout
+	case <-ctx.Done():
+		return Ticket{Depth: depth, Wait: time.Since(start)}, nil, ctx.Err()
+	}
+}
+
+// 슬롯 획득 실패 응답(cmd/hash 공통)
+func Rejected(ctx context.Context, base protocol.Res, err error) protocol.Res {
+	base.Ok = false
+	base.Error = err.Error()
+	switch {
+	case errors.Is(err, ErrQueueFull):
+		base.Code = protocol.CodeQueueFull
+	case errors.Is(err, ErrQueueTimeout):
+		base.Code = protocol.CodeQueueTimeout
+	case cancelled(ctx):
+		base.Reason = protocol.ReasonCancelled
+		base.Code = protocol.CodeCancelled
+		base.Error = ErrCancelled.Error()
+	default:
+		// 연결 종료/서버 종료로 대기 중단
+		base.Reason = protocol.ReasonKilled
+		base.Code = protocol.CodeCancelled
+	}
+	return base
+}
+
+// 현재 실행/대기 수
+func (p *Pool) Load() (running, queued int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.slots), p.queued
+}
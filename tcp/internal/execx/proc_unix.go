@@ -0,0 +1,22 @@
+//go:build !windows
+
+package execx
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// 자식들을 새 프로세스 그룹으로 묶음
+func setProcGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// 프로세스 그룹 전체 종료
+func killProcGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	// 음수 pid = 그룹 전체
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+package execx
+
+import (
+	"os/exec"
+)
+
+// 윈도우는 그룹 설정 생략(빌드만 지원, 허용 명령은 유닉스 도구)
+func setProcGroup(cmd *exec.Cmd) {}
+
+// 프로세스만 종료
+func killProcGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return cmd.Process.Kill()
+}
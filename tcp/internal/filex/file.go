@@ -1,17 +1,14 @@
 package filex
 
 import (
-	"encoding/base64"
-	"os"
-	"path/filepath"
+	"errors"
+	"io"
+	"io/fs"
 	"strings"
 
 	"golang-network-labs/tcp/internal/protocol"
 )
 
-// 파일 루트 제한
-var fileRoot = "/data"
-
 // 파일 청크 읽기
 func ReadChunk(req protocol.Req, base protocol.Res) protocol.Res {
 	// limit 기본값
@@ -36,60 +33,65 @@ func ReadChunk(req protocol.Req, base protocol.Res) protocol.Res {
 	if p == "" {
 		base.Ok = false
 		base.Error = "path required"
+		base.Code = protocol.CodeBadRequest
 		return base
 	}
 
-	// 경로 정규화
-	clean := filepath.Clean(p)
-
-	// 루트 하위로 강제
-	abs := filepath.Join(fileRoot, clean)
-
-	// 루트 탈출 방지
-	if !strings.HasPrefix(abs, fileRoot) {
+	// 루트 안에서 열기(.. 및 루트 밖 심볼릭 링크 거절)
+	f, _, err := openRead(p)
+	if err != nil {
 		base.Ok = false
-		base.Error = "invalid path"
+		base.Error = err.Error()
+		base.Code = ioCode(err)
 		return base
 	}
+	defer f.Close()
 
-	// 파일 오픈
-	f, err := os.Open(abs)
+	// 현재 크기(EOF/진행률 기준)
+	info, err := f.Stat()
 	if err != nil {
 		base.Ok = false
 		base.Error = err.Error()
+		base.Code = ioCode(err)
 		return base
 	}
-	defer f.Close()
+	size := info.Size()
 
 	// 오프셋 이동
-	if _, err := f.Seek(offset, 0); err != nil {
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
 		base.Ok = false
 		base.Error = err.Error()
+		base.Code = ioCode(err)
 		return base
 	}
 
-	// 버퍼 준비
-	buf := make([]byte, limit)
+	// 크기까지만 읽기(끝 이후 offset은 빈 청크)
+	buf := make([]byte, max(0, min(limit, size-offset)))
 
-	// 읽기 수행
-	n, err := f.Read(buf)
-	if err != nil && n == 0 {
+	// 읽기 수행(그사이 파일이 줄면 짧게 끝남)
+	n, err := io.ReadFull(f, buf)
+	short := errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+	if err != nil && !short {
 		base.Ok = false
 		base.Error = err.Error()
+		base.Code = ioCode(err)
 		return base
 	}
 
 	// 실제 청크
 	chunk := buf[:n]
 
-	// Base64 인코딩
-	base.FileB64 = base64.StdEncoding.EncodeToString(chunk)
+	// 청크 원본(전송 모드에 맞게 writer가 인코딩)
+	base.Data = chunk
+	// 청크 체크섬(받는 쪽 검증용)
+	base.CRC32C, base.SHA256 = Checksums(chunk)
 
 	// 다음 오프셋 계산
 	base.NextOffset = offset + int64(n)
 
-	// EOF 추정
-	base.EOF = n < int(limit)
+	// EOF: 파일 크기에 도달(정확히 limit 배수여도 마지막 청크에서 true)
+	base.FileSize = size
+	base.EOF = short || base.NextOffset >= size
 
 	// 성공 처리
 	base.Ok = true
@@ -97,3 +99,17 @@ func ReadChunk(req protocol.Req, base protocol.Res) protocol.Res {
 	base.Error = ""
 	return base
 }
+
+// 파일 에러 → 에러 코드
+func ioCode(err error) string {
+	switch {
+	case errors.Is(err, errEscape):
+		return protocol.CodePathEscape
+	case errors.Is(err, errUnknownRoot), errors.Is(err, fs.ErrNotExist):
+		return protocol.CodeNotFound
+	case errors.Is(err, fs.ErrPermission):
+		return protocol.CodePermissionDenied
+	default:
+		return protocol.CodeIOError
+	}
+}
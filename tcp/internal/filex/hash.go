@@ -0,0 +1,118 @@
+package filex
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang-network-labs/tcp/internal/execx"
+	"golang-network-labs/tcp/internal/protocol"
+)
+
+// CRC32C(Castagnoli) 테이블
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// 해시 계산 중 읽기 단위(취소 확인 간격)
+const hashBlock = 1 << 20
+
+// 계산 도중 파일이 바뀜
+var errChanged = errors.New("file changed while hashing")
+
+// 바이트의 CRC32C/SHA-256(hex)
+func Checksums(b []byte) (crc, sum string) {
+	s := sha256.Sum256(b)
+	return fmt.Sprintf("%08x", crc32.Checksum(b, castagnoli)), hex.EncodeToString(s[:])
+}
+
+// 파일 전체 해시(timeout_ms가 있으면 대기 포함 그 안에, cancel로 중단)
+// - 명령과 같은 실행 풀 슬롯을 사용(pool이 nil이면 제한 없음)
+func Hash(ctx context.Context, req protocol.Req, base protocol.Res, pool *execx.Pool) protocol.Res {
+	p := strings.TrimSpace(req.Path)
+	if p == "" {
+		return fail(base, errors.New("path required"), protocol.CodeBadRequest)
+	}
+
+	// 제한시간
+	if req.TimeoutMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(req.TimeoutMs)*time.Millisecond)
+		defer cancel()
+	}
+
+	// 실행 슬롯 확보(가득 차면 QUEUE_FULL, 대기 중 제한시간/cancel 반영)
+	if pool != nil {
+		t, release, err := pool.Acquire(ctx)
+		base.QueueDepth = t.Depth
+		base.QueueWaitMs = t.Wait.Milliseconds()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return fail(base, errors.New("hash timed out"), protocol.CodeTimeout)
+			}
+			return execx.Rejected(ctx, base, err)
+		}
+		defer release()
+	}
+
+	f, _, err := openRead(p)
+	if err != nil {
+		return fail(base, err, ioCode(err))
+	}
+	defer f.Close()
+
+	before, err := f.Stat()
+	if err != nil {
+		return fail(base, err, ioCode(err))
+	}
+	if before.IsDir() {
+		return fail(base, errIsDir, protocol.CodeBadRequest)
+	}
+
+	// 한 번 읽으며 두 해시 계산
+	crc := crc32.New(castagnoli)
+	sum := sha256.New()
+	w := io.MultiWriter(crc, sum)
+	buf := make([]byte, hashBlock)
+	var n int64
+	for {
+		if err := ctx.Err(); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return fail(base, errors.New("hash timed out"), protocol.CodeTimeout)
+			}
+			return fail(base, errors.New("hash cancelled"), protocol.CodeCancelled)
+		}
+		m, err := f.Read(buf)
+		w.Write(buf[:m])
+		n += int64(m)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fail(base, err, ioCode(err))
+		}
+	}
+
+	// 읽는 동안 크기/수정 시각이 바뀌면 결과를 믿을 수 없음
+	after, err := f.Stat()
+	if err != nil {
+		return fail(base, err, ioCode(err))
+	}
+	if n != after.Size() || !after.ModTime().Equal(before.ModTime()) {
+		return fail(base, errChanged, protocol.CodeIOError)
+	}
+
+	base.Ok = true
+	base.Output = "file hashed"
+	base.FileSize = n
+	base.CRC32C = fmt.Sprintf("%08x", crc.Sum32())
+	base.SHA256 = hex.EncodeToString(sum.Sum(nil))
+	e := entryOf(filepath.Base(f.Name()), Canonical(p), after)
+	base.Entry = &e
+	return base
+}
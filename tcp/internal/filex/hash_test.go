@@ -0,0 +1,66 @@
+package filex
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang-network-labs/tcp/internal/execx"
+	"golang-network-labs/tcp/internal/protocol"
+)
+
+// 해시는 실행 풀 슬롯을 기다리고, 못 얻으면 QUEUE_FULL/TIMEOUT/CANCELLED
+func TestHashUsesPool(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("abc"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Configure("data=" + dir + ":ro"); err != nil {
+		t.Fatal(err)
+	}
+	req := protocol.Req{Type: "hash", Path: "/a.txt"}
+
+	// 슬롯이 비어 있으면 바로 계산
+	pool := execx.NewPool(execx.Options{Workers: 1, QueueSize: 1})
+	res := Hash(context.Background(), req, protocol.Res{}, pool)
+	if !res.Ok || res.SHA256 != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Fatalf("hash = %+v", res)
+	}
+
+	// 실행중 1 + 대기 1이 차면 바로 거절
+	_, release, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	waiting, stop := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _, _ = pool.Acquire(waiting)
+	}()
+	for _, queued := pool.Load(); queued == 0; _, queued = pool.Load() {
+		time.Sleep(time.Millisecond)
+	}
+	if res := Hash(context.Background(), req, protocol.Res{}, pool); res.Code != protocol.CodeQueueFull {
+		t.Fatalf("full pool: code = %s", res.Code)
+	}
+	stop()
+	<-done
+
+	// 대기 중 제한시간
+	req.TimeoutMs = 20
+	if res := Hash(context.Background(), req, protocol.Res{}, pool); res.Code != protocol.CodeTimeout {
+		t.Fatalf("timeout while queued: code = %s", res.Code)
+	}
+
+	// 대기 중 cancel
+	req.TimeoutMs = 0
+	ctx, cancel := context.WithCancelCause(context.Background())
+	time.AfterFunc(20*time.Millisecond, func() { cancel(execx.ErrCancelled) })
+	if res := Hash(ctx, req, protocol.Res{}, pool); res.Code != protocol.CodeCancelled {
+		t.Fatalf("cancel while queued: code = %s", res.Code)
+	}
+}
@@ -0,0 +1,169 @@
+package filex
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang-network-labs/tcp/internal/protocol"
+)
+
+// list 한 페이지 항목 수
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+)
+
+// 디렉터리 아님
+var errNotDir = errors.New("not a directory")
+
+// 디렉터리 항목 조회(이름순, offset/limit 페이지, pattern glob)
+func List(req protocol.Req, base protocol.Res) protocol.Res {
+	// 페이지 크기
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+	offset := max(req.Offset, 0)
+
+	// 패턴 검사(잘못된 패턴은 BAD_REQUEST)
+	pattern := strings.TrimSpace(req.Pattern)
+	if pattern != "" {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fail(base, fmt.Errorf("invalid pattern: %w", err), protocol.CodeBadRequest)
+		}
+	}
+
+	// 디렉터리 열기(빈 경로는 기본 루트)
+	f, _, err := openRead(req.Path)
+	if err != nil {
+		return fail(base, err, ioCode(err))
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return fail(base, err, ioCode(err))
+	}
+	if !fi.IsDir() {
+		return fail(base, errNotDir, protocol.CodeBadRequest)
+	}
+
+	// 전체 항목(ReadDir는 순서를 보장하지 않음)
+	all, err := f.ReadDir(-1)
+	if err != nil {
+		return fail(base, err, ioCode(err))
+	}
+	slices.SortFunc(all, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+
+	// 필터
+	matched := all[:0]
+	for _, e := range all {
+		// 진행중인 업로드의 임시 파일은 숨김
+		if isUploadTemp(e.Name()) {
+			continue
+		}
+		if pattern != "" {
+			if ok, _ := path.Match(pattern, e.Name()); !ok {
+				continue
+			}
+		}
+		matched = append(matched, e)
+	}
+
+	// 페이지 자르기
+	start := min(offset, int64(len(matched)))
+	end := min(start+limit, int64(len(matched)))
+	dir := Canonical(req.Path)
+	entries := make([]protocol.FileEntry, 0, end-start)
+	for _, e := range matched[start:end] {
+		// 링크는 링크 자체 정보(Lstat)
+		info, err := e.Info()
+		if err != nil {
+			// 조회 사이에 지워진 항목은 건너뜀
+			continue
+		}
+		entries = append(entries, entryOf(e.Name(), joinCanonical(dir, e.Name()), info))
+	}
+
+	base.Ok = true
+	base.Output = fmt.Sprintf("%d entries", len(entries))
+	base.Entries = entries
+	base.NextOffset = end
+	base.EOF = end >= int64(len(matched))
+	return base
+}
+
+// 파일 정보 조회(링크는 따라간 대상, 루트 밖이면 PATH_ESCAPE)
+func Stat(req protocol.Req, base protocol.Res) protocol.Res {
+	r, rel, err := resolve(req.Path)
+	if err != nil {
+		return fail(base, err, ioCode(err))
+	}
+	info, err := r.fs.Stat(rel)
+	if err != nil {
+		err = r.classify(rel, err)
+		return fail(base, err, ioCode(err))
+	}
+
+	// 루트 자신은 루트 이름
+	name := filepath.Base(rel)
+	if rel == "." {
+		name = r.Name
+	}
+	e := entryOf(name, Canonical(req.Path), info)
+
+	base.Ok = true
+	base.Output = "file stat"
+	base.Entry = &e
+	return base
+}
+
+// fs.FileInfo → 응답 항목
+func entryOf(name, p string, info fs.FileInfo) protocol.FileEntry {
+	return protocol.FileEntry{
+		Name:    name,
+		Path:    p,
+		Type:    entryType(info.Mode()),
+		Size:    info.Size(),
+		Mode:    fmt.Sprintf("%04o", info.Mode().Perm()),
+		ModTime: info.ModTime().UTC(),
+	}
+}
+
+// 모드 → 항목 종류
+func entryType(m fs.FileMode) string {
+	switch {
+	case m.IsRegular():
+		return protocol.EntryFile
+	case m.IsDir():
+		return protocol.EntryDir
+	case m&fs.ModeSymlink != 0:
+		return protocol.EntrySymlink
+	default:
+		return protocol.EntryOther
+	}
+}
+
+// 정규 경로 + 이름("logs:/" + "a" → "logs:/a")
+func joinCanonical(dir, name string) string {
+	if root, rest := SplitRoot(dir); root != "" {
+		return root + ":" + path.Join(rest, name)
+	}
+	return path.Join(dir, name)
+}
+
+// 실패 응답
+func fail(base protocol.Res, err error, code string) protocol.Res {
+	base.Ok = false
+	base.Error = err.Error()
+	base.Code = code
+	return base
+}
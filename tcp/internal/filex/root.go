@@ -0,0 +1,262 @@
+package filex
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// FILE_ROOTS 기본값
+const DefaultRoots = "data=/data:ro"
+
+// 루트 이름 형식("logs:app.log"의 logs)
+var rootNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+
+// 경로 에러
+var (
+	errUnknownRoot = errors.New("unknown root")
+	errEscape      = errors.New("invalid path")
+)
+
+// 이름 붙은 파일 루트
+type Root struct {
+	// 요청 경로의 접두사("logs:")
+	Name string
+	// 실제 디렉터리
+	Dir string
+	// 쓰기 허용(rw)
+	Writable bool
+
+	// 루트 밖으로 못 나가는 핸들(심볼릭 링크 포함)
+	fs *os.Root
+}
+
+// 설정된 루트 목록
+type rootSet struct {
+	// 순서 유지(로그용)
+	list   []*Root
+	byName map[string]*Root
+	// 접두사 없는 경로의 루트(목록 첫 번째)
+	def *Root
+}
+
+// 적용중인 루트(Configure 전에는 비어 있음)
+var roots atomic.Pointer[rootSet]
+
+func init() {
+	roots.Store(&rootSet{byName: map[string]*Root{}})
+}
+
+// 루트 설정("name=dir[:ro|:rw],...", 첫 번째가 기본 루트)
+// - 열지 못한 디렉터리는 에러(기존 루트 유지)
+func Configure(spec string) ([]*Root, error) {
+	set := &rootSet{byName: map[string]*Root{}}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		r, err := parseRoot(entry)
+		if err != nil {
+			closeRoots(set.list)
+			return nil, err
+		}
+		if _, dup := set.byName[r.Name]; dup {
+			closeRoots(set.list)
+			return nil, fmt.Errorf("file root %q: duplicate name", r.Name)
+		}
+
+		// 디렉터리 핸들(이후 모든 접근은 이 핸들 기준)
+		r.fs, err = os.OpenRoot(r.Dir)
+		if err != nil {
+			closeRoots(set.list)
+			return nil, fmt.Errorf("file root %q: %w", r.Name, err)
+		}
+		set.list = append(set.list, r)
+		set.byName[r.Name] = r
+	}
+	if len(set.list) == 0 {
+		return nil, errors.New("no file roots")
+	}
+	set.def = set.list[0]
+
+	// 교체(이전 핸들은 처리중인 요청이 있을 수 있어 닫지 않음)
+	roots.Store(set)
+	return set.list, nil
+}
+
+// "name=dir[:ro|:rw]" 한 항목
+func parseRoot(entry string) (*Root, error) {
+	name, dir, ok := strings.Cut(entry, "=")
+	name = strings.TrimSpace(name)
+	if !ok || !rootNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("file root %q: must be name=dir[:ro|:rw] with a lowercase name", entry)
+	}
+
+	// 모드(기본 ro)
+	r := &Root{Name: name}
+	switch {
+	case strings.HasSuffix(dir, ":rw"):
+		r.Writable = true
+		dir = strings.TrimSuffix(dir, ":rw")
+	case strings.HasSuffix(dir, ":ro"):
+		dir = strings.TrimSuffix(dir, ":ro")
+	}
+
+	dir = strings.TrimSpace(dir)
+	if !filepath.IsAbs(dir) {
+		return nil, fmt.Errorf("file root %q: directory must be absolute", name)
+	}
+	r.Dir = filepath.Clean(dir)
+	return r, nil
+}
+
+// 설정 실패 시 열어둔 핸들 정리
+func closeRoots(list []*Root) {
+	for _, r := range list {
+		if r.fs != nil {
+			_ = r.fs.Close()
+		}
+	}
+}
+
+// 설정된 루트 목록
+func Roots() []*Root { return roots.Load().list }
+
+// 기본 루트(없으면 nil)
+func DefaultRoot() *Root { return roots.Load().def }
+
+// 이름으로 루트 찾기(없으면 nil)
+func Lookup(name string) *Root { return roots.Load().byName[name] }
+
+// 모드 표시용
+func (r *Root) Mode() string {
+	if r.Writable {
+		return "rw"
+	}
+	return "ro"
+}
+
+// "logs:a/b" → ("logs", "a/b"), 접두사가 없으면 ("", p)
+func SplitRoot(p string) (name, rest string) {
+	i := strings.IndexByte(p, ':')
+	if i > 0 && rootNamePattern.MatchString(p[:i]) {
+		return p[:i], p[i+1:]
+	}
+	return "", p
+}
+
+// 요청 경로 → 루트 + 루트 기준 상대경로
+func resolve(p string) (*Root, string, error) {
+	set := roots.Load()
+	name, rest := SplitRoot(strings.TrimSpace(p))
+
+	r := set.def
+	if name != "" {
+		r = set.byName[name]
+	}
+	if r == nil {
+		return nil, "", errUnknownRoot
+	}
+
+	// "/a/../b" → "b", 루트 위로 올라가는 경로는 거절
+	rel := filepath.Clean(rest)
+	if filepath.IsAbs(rel) {
+		rel = strings.TrimPrefix(rel, string(filepath.Separator))
+		if rel == "" {
+			rel = "."
+		}
+	}
+	if !filepath.IsLocal(rel) && rel != "." {
+		return nil, "", errEscape
+	}
+	return r, rel, nil
+}
+
+// 권한 검사용 정규 경로("/a/b"는 기본 루트, 그 외 "logs:/a/b")
+func Canonical(p string) string {
+	set := roots.Load()
+	name, rest := SplitRoot(strings.TrimSpace(p))
+	rel := path.Clean("/" + rest)
+	if name == "" || (set.def != nil && name == set.def.Name) {
+		return rel
+	}
+	return name + ":" + rel
+}
+
+// 권한 검사용 실제 경로(루트 안 심볼릭 링크를 따라간 Canonical 경로)
+// - 없는 경로는 가장 가까운 기존 상위를 따라간 뒤 나머지를 붙임
+// - 루트 밖이거나 해석할 수 없으면 ""(열기 단계에서 거절)
+func Resolved(p string) string {
+	r, rel, err := resolve(p)
+	if err != nil {
+		return ""
+	}
+	base, err := filepath.EvalSymlinks(r.Dir)
+	if err != nil {
+		return ""
+	}
+	rest := ""
+	for q := rel; ; q = filepath.Dir(q) {
+		target, err := filepath.EvalSymlinks(filepath.Join(r.Dir, q))
+		if err == nil {
+			out, err := filepath.Rel(base, target)
+			if err != nil || !filepath.IsLocal(out) && out != "." {
+				return ""
+			}
+			return Canonical(r.Name + ":" + filepath.ToSlash(filepath.Join(out, rest)))
+		}
+		if q == "." {
+			return ""
+		}
+		rest = filepath.Join(filepath.Base(q), rest)
+	}
+}
+
+// 루트 안에서 읽기 전용 열기(심볼릭 링크가 루트 밖을 가리키면 거절)
+func openRead(p string) (*os.File, *Root, error) {
+	r, rel, err := resolve(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := r.fs.Open(rel)
+	if err != nil {
+		return nil, r, r.classify(rel, err)
+	}
+	return f, r, nil
+}
+
+// os.Root 에러 중 루트 탈출을 구분(존재/권한 에러는 그대로)
+func (r *Root) classify(rel string, err error) error {
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+		return err
+	}
+	if r.escapes(rel) {
+		return errEscape
+	}
+	return err
+}
+
+// 링크를 따라간 실제 경로가 루트 밖인지(없는 경로는 가장 가까운 상위로 판단)
+func (r *Root) escapes(rel string) bool {
+	base, err := filepath.EvalSymlinks(r.Dir)
+	if err != nil {
+		return false
+	}
+	for p := rel; ; p = filepath.Dir(p) {
+		target, err := filepath.EvalSymlinks(filepath.Join(r.Dir, p))
+		if err == nil {
+			out, err := filepath.Rel(base, target)
+			return err != nil || !filepath.IsLocal(out) && out != "."
+		}
+		if p == "." {
+			return false
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package filex
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang-network-labs/tcp/internal/protocol"
+)
+
+// write 단계
+const (
+	OpOpen   = "open"
+	OpAppend = "append"
+	OpCommit = "commit"
+	OpAbort  = "abort"
+)
+
+// 업로드 제한
+var (
+	// 파일 하나의 최대 크기(FILE_MAX_UPLOAD_BYTES)
+	MaxUpload int64 = 1 << 30
+	// 동시에 열린 업로드 수
+	maxUploads = 64
+	// 사용자 한 명이 동시에 열 수 있는 업로드 수(한 사용자가 자리를 독차지하지 않게)
+	maxUserUploads = 8
+	// 마지막 청크 이후 이 시간이 지나면 임시 파일 삭제
+	uploadIdle = 10 * time.Minute
+)
+
+// 임시 파일 이름: .upload-<id>.tmp
+const (
+	uploadTempPrefix = ".upload-"
+	uploadTempSuffix = ".tmp"
+)
+
+// 업로드 에러
+var (
+	errUnknownUpload = errors.New("unknown upload")
+	errTooManyUpload = errors.New("too many uploads in progress")
+	errTooManyOwn    = errors.New("too many uploads in progress for this user")
+	errRootPath      = errors.New("path names a root, not a file")
+	errIsDir         = errors.New("path is a directory")
+)
+
+// 진행중인 업로드 한 건(임시 파일은 대상과 같은 디렉터리 → rename이 원자적)
+type upload struct {
+	// 같은 업로드의 청크는 순서대로
+	mu sync.Mutex
+
+	id   string
+	user string
+	root *Root
+	// 대상/임시 파일(루트 기준)
+	rel string
+	tmp string
+	f   *os.File
+	// 받은 바이트 해시/크기
+	sum  hash.Hash
+	size int64
+	// 마지막 사용 시각(만료 판단)
+	touched time.Time
+	// commit/abort/만료로 끝남
+	done bool
+}
+
+// 업로드 목록(연결과 무관하게 유지, API 풀의 어느 연결로 와도 됨)
+var uploads = struct {
+	sync.Mutex
+	m map[string]*upload
+}{m: map[string]*upload{}}
+
+// 파일 쓰기(op별 분기)
+func Write(req protocol.Req, base protocol.Res) protocol.Res {
+	// 만료된 업로드 정리
+	SweepUploads()
+
+	switch req.Op {
+	case OpOpen:
+		return openUpload(req, base)
+	case OpAppend, OpCommit, OpAbort:
+	default:
+		return fail(base, fmt.Errorf("unknown write op %q", req.Op), protocol.CodeBadRequest)
+	}
+
+	// 업로드 찾기(다른 사용자의 업로드는 없는 것으로)
+	u := findUpload(req.UploadID, req.UserID)
+	if u == nil {
+		return fail(base, errUnknownUpload, protocol.CodeNotFound)
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.done {
+		return fail(base, errUnknownUpload, protocol.CodeNotFound)
+	}
+	u.touched = time.Now()
+	base.UploadID = u.id
+
+	switch req.Op {
+	case OpAppend:
+		return u.append(req, base)
+	case OpCommit:
+		return u.commit(req, base)
+	default:
+		u.discard()
+		base.Ok = true
+		base.Output = "upload aborted"
+		return base
+	}
+}
+
+// 임시 파일 만들고 업로드 ID 발급
+func openUpload(req protocol.Req, base protocol.Res) protocol.Res {
+	if strings.TrimSpace(req.Path) == "" {
+		return fail(base, errors.New("path required"), protocol.CodeBadRequest)
+	}
+	r, rel, err := resolve(req.Path)
+	if err != nil {
+		return fail(base, err, ioCode(err))
+	}
+	if !r.Writable {
+		return fail(base, fmt.Errorf("root %q is read-only", r.Name), protocol.CodeReadOnly)
+	}
+	if rel == "." {
+		return fail(base, errRootPath, protocol.CodeBadRequest)
+	}
+
+	// 디렉터리는 덮어쓰지 않음
+	if fi, err := r.fs.Lstat(rel); err == nil && fi.IsDir() {
+		return fail(base, errIsDir, protocol.CodeBadRequest)
+	}
+
+	id, err := newUploadID()
+	if err != nil {
+		return fail(base, err, protocol.CodeInternal)
+	}
+
+	// 동시 업로드 수 제한(전체 + 사용자별, 디렉터리를 만들기 전에 자리 확보)
+	u := &upload{id: id, user: req.UserID, root: r, rel: rel, sum: sha256.New(), touched: time.Now()}
+	uploads.Lock()
+	if len(uploads.m) >= maxUploads {
+		uploads.Unlock()
+		return fail(base, errTooManyUpload, protocol.CodeQueueFull)
+	}
+	if userUploads(req.UserID) >= maxUserUploads {
+		uploads.Unlock()
+		return fail(base, errTooManyOwn, protocol.CodeQueueFull)
+	}
+	uploads.m[id] = u
+	uploads.Unlock()
+
+	// 상위 디렉터리(없으면 생성, 루트 밖 링크면 거절)
+	dir := filepath.Dir(rel)
+	if err := r.fs.MkdirAll(dir, 0o755); err != nil {
+		removeUpload(id)
+		err = r.classify(dir, err)
+		return fail(base, err, ioCode(err))
+	}
+
+	// 숨김 임시 파일(이미 있으면 실패)
+	u.tmp = filepath.Join(dir, uploadTempPrefix+id+uploadTempSuffix)
+	u.f, err = r.fs.OpenFile(u.tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		removeUpload(id)
+		err = r.classify(u.tmp, err)
+		return fail(base, err, ioCode(err))
+	}
+
+	base.Ok = true
+	base.Output = "upload opened"
+	base.UploadID = id
+	base.NextOffset = 0
+	return base
+}
+
+// 청크 이어 쓰기(오프셋이 받은 크기와 같아야 함)
+func (u *upload) append(req protocol.Req, base protocol.Res) protocol.Res {
+	base.NextOffset = u.size
+
+	data := req.Data
+	if len(data) == 0 && req.DataB64 != "" {
+		b, err := base64.StdEncoding.DecodeString(req.DataB64)
+		if err != nil {
+			return fail(base, errors.New("bad data_b64"), protocol.CodeBadRequest)
+		}
+		data = b
+	}
+
+	// 재전송/누락 청크는 현재 크기를 알려 이어서 보내게 함
+	if req.Offset != u.size {
+		return fail(base, fmt.Errorf("offset %d, expected %d", req.Offset, u.size), protocol.CodeOffsetMismatch)
+	}
+	if u.size+int64(len(data)) > MaxUpload {
+		u.discard()
+		return fail(base, fmt.Errorf("upload larger than %d bytes", MaxUpload), protocol.CodeFileTooLarge)
+	}
+
+	n, err := u.f.Write(data)
+	u.sum.Write(data[:n])
+	u.size += int64(n)
+	base.NextOffset = u.size
+	if err != nil {
+		u.discard()
+		return fail(base, err, ioCode(err))
+	}
+
+	base.Ok = true
+	base.Output = "chunk written"
+	return base
+}
+
+// SHA-256 확인 후 대상 이름으로 원자적 교체
+func (u *upload) commit(req protocol.Req, base protocol.Res) protocol.Res {
+	base.NextOffset = u.size
+
+	want := strings.ToLower(strings.TrimSpace(req.SHA256))
+	if len(want) != sha256.Size*2 {
+		return fail(base, errors.New("sha256 required (64 hex characters)"), protocol.CodeBadRequest)
+	}
+	got := hex.EncodeToString(u.sum.Sum(nil))
+	if got != want {
+		u.discard()
+		return fail(base, fmt.Errorf("sha256 mismatch: received %s", got), protocol.CodeChecksumMismatch)
+	}
+
+	// 디스크 반영 후 닫기
+	if err := u.f.Sync(); err != nil {
+		u.discard()
+		return fail(base, err, ioCode(err))
+	}
+	if err := u.f.Close(); err != nil {
+		u.discard()
+		return fail(base, err, ioCode(err))
+	}
+
+	// 교체 전 대상 존재 여부(새로 생긴 파일인지)
+	_, statErr := u.root.fs.Lstat(u.rel)
+	created := errors.Is(statErr, fs.ErrNotExist)
+	if err := u.root.fs.Rename(u.tmp, u.rel); err != nil {
+		u.discard()
+		err = u.root.classify(u.rel, err)
+		return fail(base, err, ioCode(err))
+	}
+	u.done = true
+	removeUpload(u.id)
+
+	// 디렉터리 항목도 디스크에 반영(실패는 무시)
+	if d, err := u.root.fs.Open(filepath.Dir(u.rel)); err == nil {
+		_ = d.Sync()
+		_ = d.Close()
+	}
+
+	p := u.root.Name + ":" + filepath.ToSlash(u.rel)
+	base.Ok = true
+	base.Output = "upload committed"
+	base.Created = created
+	if info, err := u.root.fs.Stat(u.rel); err == nil {
+		e := entryOf(filepath.Base(u.rel), Canonical(p), info)
+		base.Entry = &e
+	}
+	return base
+}
+
+// 임시 파일 삭제 + 목록에서 제거(u.mu 잡은 상태)
+func (u *upload) discard() {
+	if u.done {
+		return
+	}
+	u.done = true
+	if u.f != nil {
+		_ = u.f.Close()
+	}
+	_ = u.root.fs.Remove(u.tmp)
+	removeUpload(u.id)
+}
+
+// ID + 소유자로 찾기
+func findUpload(id, user string) *upload {
+	uploads.Lock()
+	defer uploads.Unlock()
+	u := uploads.m[id]
+	if u == nil || u.user != user {
+		return nil
+	}
+	return u
+}
+
+// 사용자의 진행중인 업로드 수(uploads 잠금 상태, 목록은 maxUploads 이하)
+func userUploads(user string) int {
+	n := 0
+	for _, u := range uploads.m {
+		if u.user == user {
+			n++
+		}
+	}
+	return n
+}
+
+// 목록에서 제거
+func removeUpload(id string) {
+	uploads.Lock()
+	delete(uploads.m, id)
+	uploads.Unlock()
+}
+
+// 오래 방치된 업로드 정리(Write마다 + tcp main의 주기 실행)
+func SweepUploads() {
+	uploads.Lock()
+	list := make([]*upload, 0, len(uploads.m))
+	for _, u := range uploads.m {
+		list = append(list, u)
+	}
+	uploads.Unlock()
+
+	for _, u := range list {
+		// 청크 처리중이면 다음 기회에
+		if !u.mu.TryLock() {
+			continue
+		}
+		if time.Since(u.touched) > uploadIdle {
+			u.discard()
+		}
+		u.mu.Unlock()
+	}
+}
+
+// 업로드 임시 파일인지(목록에서 숨김)
+func isUploadTemp(name string) bool {
+	return strings.HasPrefix(name, uploadTempPrefix) && strings.HasSuffix(name, uploadTempSuffix)
+}
+
+// 업로드 ID(임시 파일 이름에도 사용)
+func newUploadID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,70 @@
+package filex
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang-network-labs/tcp/internal/protocol"
+)
+
+// 한 사용자가 업로드 자리를 다 차지하지 못함
+func TestOpenUploadPerUserLimit(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Configure("data=" + dir + ":rw"); err != nil {
+		t.Fatal(err)
+	}
+	open := func(user string, i int) protocol.Res {
+		res := Write(protocol.Req{Type: "write", Op: OpOpen, UserID: user, Path: fmt.Sprintf("%s/%d.bin", user, i)}, protocol.Res{})
+		if res.Ok {
+			t.Cleanup(func() {
+				Write(protocol.Req{Type: "write", Op: OpAbort, UserID: user, UploadID: res.UploadID}, protocol.Res{})
+			})
+		}
+		return res
+	}
+
+	for i := 0; i < maxUserUploads; i++ {
+		if res := open("a", i); !res.Ok {
+			t.Fatalf("open %d: %s %s", i, res.Code, res.Error)
+		}
+	}
+	if res := open("a", maxUserUploads); res.Ok || res.Code != protocol.CodeQueueFull {
+		t.Fatalf("over user limit: ok=%v code=%s", res.Ok, res.Code)
+	}
+	// 거절된 업로드는 디렉터리도 만들지 않음
+	res := Write(protocol.Req{Type: "write", Op: OpOpen, UserID: "a", Path: "new/x.bin"}, protocol.Res{})
+	if res.Ok {
+		t.Fatal("over user limit accepted")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new")); !os.IsNotExist(err) {
+		t.Fatalf("rejected upload created its directory: %v", err)
+	}
+	// 다른 사용자는 영향 없음
+	if res := open("b", 0); !res.Ok {
+		t.Fatalf("other user: %s %s", res.Code, res.Error)
+	}
+}
+
+// 진행중인 업로드의 임시 파일은 목록에 나오지 않음
+func TestListHidesUploadTemp(t *testing.T) {
+	if _, err := Configure("data=" + t.TempDir() + ":rw"); err != nil {
+		t.Fatal(err)
+	}
+	res := Write(protocol.Req{Type: "write", Op: OpOpen, UserID: "a", Path: "d/f.bin"}, protocol.Res{})
+	if !res.Ok {
+		t.Fatalf("open: %s %s", res.Code, res.Error)
+	}
+	t.Cleanup(func() {
+		Write(protocol.Req{Type: "write", Op: OpAbort, UserID: "a", UploadID: res.UploadID}, protocol.Res{})
+	})
+
+	ls := List(protocol.Req{Type: "list", Path: "d"}, protocol.Res{})
+	if !ls.Ok {
+		t.Fatalf("list: %s %s", ls.Code, ls.Error)
+	}
+	if len(ls.Entries) != 0 {
+		t.Fatalf("entries = %+v, want none", ls.Entries)
+	}
+}
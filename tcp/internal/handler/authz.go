@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"strings"
+
+	"golang-network-labs/tcp/internal/authz"
+	"golang-network-labs/tcp/internal/execx"
+	"golang-network-labs/tcp/internal/filex"
+	"golang-network-labs/tcp/internal/protocol"
+)
+
+// 요청 타입 + 대상(명령/경로) 권한 검사(규칙이 없으면 통과)
+func authorize(req protocol.Req) error {
+	rules := authz.Current()
+	if err := rules.CheckType(req.UserID, req.Type); err != nil {
+		return err
+	}
+
+	switch req.Type {
+	case "cmd":
+		// 빈 명령은 execx가 BAD_REQUEST로 처리
+		name, paths := execx.CmdTargets(req.Cmd)
+		if name == "" {
+			return nil
+		}
+		return rules.CheckCmd(req.UserID, name, paths)
+	case "file", "hash":
+		// 빈 경로는 filex가 BAD_REQUEST로 처리
+		if strings.TrimSpace(req.Path) == "" {
+			return nil
+		}
+		return rules.CheckPath(req.UserID, req.Path)
+	case "list", "stat":
+		// 빈 경로는 기본 루트
+		return rules.CheckPath(req.UserID, req.Path)
+	case "write":
+		// 경로는 open에서만 검사(이후 단계는 업로드 소유자만 가능)
+		if req.Op != filex.OpOpen || strings.TrimSpace(req.Path) == "" {
+			return nil
+		}
+		return rules.CheckPath(req.UserID, req.Path)
+	}
+	return nil
+}
+
+// 실행 전 거절 응답(스트림 요청은 exit 이벤트로)
+func (h *Handler) reject(s *session, req protocol.Req, res protocol.Res) {
+	if req.Type == "cmd" && req.Stream {
+		_ = s.w.WriteEvent(protocol.Event{Event: protocol.EventExit, RequestID: req.RequestID, Result: &res})
+		return
+	}
+	_ = s.w.WriteRes(res)
+}
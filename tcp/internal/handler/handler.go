@@ -2,55 +2,465 @@ package handler
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
+	"golang-network-labs/tcp/internal/auth"
 	"golang-network-labs/tcp/internal/execx"
 	"golang-network-labs/tcp/internal/filex"
 	"golang-network-labs/tcp/internal/protocol"
 )
 
+// 연결당 동시 처리 요청 수 기본값
+const defaultMaxInFlight = 32
+
+// 연결 제한시간 기본값
+const (
+	defaultHeaderTimeout = 10 * time.Second
+	defaultReadTimeout   = 30 * time.Second
+	defaultIdleTimeout   = 120 * time.Second
+	defaultWriteTimeout  = 30 * time.Second
+)
+
+// 제한시간 초과 단계(OnTimeout 인자)
+const (
+	// 연결 후 첫 메시지(TLS 핸드셰이크 포함)까지
+	TimeoutHeader = "header"
+	// 메시지 시작 후 끝까지
+	TimeoutRead = "read"
+	// 처리중 요청 없이 다음 메시지까지
+	TimeoutIdle = "idle"
+	// 응답 메시지 한 건 쓰기
+	TimeoutWrite = "write"
+)
+
+// 지원 요청 타입(hello 응답의 caps)
+var requestTypes = []string{"ping", "cmd", "file", "list", "stat", "hash", "write", "cancel", "policy"}
+
+// 핸들러 설정
+type Config struct {
+	// 명령 실행 제한
+	Exec execx.Options
+	// 연결당 동시 처리 요청 수
+	MaxInFlight int
+	// 메시지 최대 크기(줄/프레임 공통)
+	MaxFrame int
+	// mTLS 허용 클라이언트 CN(비면 모두 허용)
+	AllowedClients []string
+	// 요청 서명 검증(nil이면 검사 안 함)
+	Verifier *auth.Verifier
+
+	// 연결 후 첫 메시지를 다 받을 때까지(TLS 핸드셰이크 포함)
+	HeaderTimeout time.Duration
+	// 메시지 첫 바이트 이후 끝까지
+	ReadTimeout time.Duration
+	// 처리중 요청이 없을 때 다음 메시지까지
+	IdleTimeout time.Duration
+	// 응답 메시지 한 건 쓰기
+	WriteTimeout time.Duration
+	// 제한시간 초과로 연결을 닫을 때 호출(단계 전달)
+	OnTimeout func(stage string)
+}
+
 // 핸들러 본체
-type Handler struct{}
+type Handler struct {
+	cfg Config
+	// 허용 CN 집합
+	allowed map[string]bool
+
+	// 종료 1단계: 새 요청 수신 중단
+	drain     context.Context
+	stopReads context.CancelFunc
+	// 종료 2단계: 처리중 요청 취소(자식 프로세스 kill)
+	base  context.Context
+	abort context.CancelFunc
+}
 
 // 핸들러 생성
-func New() *Handler {
-	// 단순 생성
-	return &Handler{}
+func New(cfg Config) *Handler {
+	// 기본값 보정
+	if cfg.MaxInFlight <= 0 {
+		cfg.MaxInFlight = defaultMaxInFlight
+	}
+	if cfg.MaxFrame <= 0 {
+		cfg.MaxFrame = protocol.DefaultMaxFrame
+	}
+	if cfg.HeaderTimeout <= 0 {
+		cfg.HeaderTimeout = defaultHeaderTimeout
+	}
+	if cfg.ReadTimeout <= 0 {
+		cfg.ReadTimeout = defaultReadTimeout
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = defaultIdleTimeout
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = defaultWriteTimeout
+	}
+	if cfg.OnTimeout == nil {
+		cfg.OnTimeout = func(string) {}
+	}
+	// 모든 연결이 공유하는 실행 풀
+	if cfg.Exec.Pool == nil {
+		cfg.Exec.Pool = execx.NewPool(cfg.Exec)
+	}
+	// 허용 CN 집합
+	allowed := make(map[string]bool, len(cfg.AllowedClients))
+	for _, cn := range cfg.AllowedClients {
+		allowed[cn] = true
+	}
+	// 종료 신호
+	drain, stopReads := context.WithCancel(context.Background())
+	base, abort := context.WithCancel(context.Background())
+	// 설정 보관
+	return &Handler{
+		cfg:       cfg,
+		allowed:   allowed,
+		drain:     drain,
+		stopReads: stopReads,
+		base:      base,
+		abort:     abort,
+	}
 }
 
-// 연결 처리
+// 모든 연결에서 새 요청 수신 중단(처리중 요청은 계속)
+func (h *Handler) Drain() { h.stopReads() }
+
+// 처리중 요청 모두 취소
+func (h *Handler) Abort() { h.abort() }
+
+// 연결 단위 상태
+type session struct {
+	// 응답 쓰기(직렬화)
+	w *protocol.Writer
+	// 연결 주소
+	local  string
+	remote string
+	// TLS 상대(mTLS 인증서 주체)
+	peer Peer
+
+	// 처리중 request_id
+	mu       sync.Mutex
+	inFlight map[string]context.CancelCauseFunc
+}
+
+// 처리중 요청 등록(중복 ID 거절)
+func (s *session) begin(id string, cancel context.CancelCauseFunc) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, dup := s.inFlight[id]; dup {
+		return false
+	}
+	s.inFlight[id] = cancel
+	return true
+}
+
+// 처리 완료
+func (s *session) end(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.inFlight, id)
+}
+
+// 처리중 요청 취소(없으면 false)
+func (s *session) cancel(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cancel, ok := s.inFlight[id]
+	if ok {
+		cancel(execx.ErrCancelled)
+	}
+	return ok
+}
+
+// 처리중 요청 존재 여부
+func (s *session) busy() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.inFlight) > 0
+}
+
+// 연결 처리(한 연결에서 여러 요청을 동시에 처리)
 func (h *Handler) Handle(conn net.Conn) {
 
 	defer conn.Close()
 
-	// 연결 주소 확보
-	local := conn.LocalAddr().String()
-	remote := conn.RemoteAddr().String()
+	// 종료 시작 시 읽기만 깨움(응답 쓰기는 유지)
+	stop := context.AfterFunc(h.drain, func() {
+		_ = conn.SetReadDeadline(time.Now())
+	})
+	defer stop()
 
-	// 한 줄 읽기 준비
-	br := bufio.NewReader(conn)
+	// 첫 메시지까지 제한시간(핸드셰이크/모드 바이트 포함)
+	_ = conn.SetDeadline(time.Now().Add(h.cfg.HeaderTimeout))
+	if h.draining() {
+		return
+	}
 
-	// 한 줄 수신
-	line, err := br.ReadBytes('\n')
+	// TLS면 핸드셰이크 + 클라이언트 인증서 확인
+	peer, err := peerOf(conn)
 	if err != nil {
+		if isTimeout(err) {
+			h.cfg.OnTimeout(TimeoutHeader)
+		}
+		log.Printf("tls handshake failed remote=%s: %v", conn.RemoteAddr(), err)
+		return
+	}
+	// 응답 쓰기 제한시간은 메시지마다 Writer가 설정
+	_ = conn.SetWriteDeadline(time.Time{})
+	if !h.peerAllowed(peer) {
+		log.Printf("tls client rejected remote=%s subject=%q", conn.RemoteAddr(), peer.Subject)
 		return
 	}
+	if peer.TLS {
+		log.Printf("tls client remote=%s subject=%q", conn.RemoteAddr(), peer.Subject)
+	}
 
-	// 요청 파싱
-	var req protocol.Req
-	if err := json.Unmarshal(line, &req); err != nil {
-		// 파싱 실패 응답
-		protocol.Res{
-			Ok:        false,
-			Error:     "bad json",
-			TcpLocal:  local,
-			TcpRemote: remote,
-		}.WriteLine(conn)
+	// 첫 바이트로 줄/프레임 모드 결정
+	br := bufio.NewReader(conn)
+	codec, err := protocol.ServerCodec(conn, br, h.cfg.MaxFrame)
+	if err != nil {
+		if isTimeout(err) && !h.draining() {
+			h.cfg.OnTimeout(TimeoutHeader)
+		}
 		return
 	}
 
+	// 연결 상태 준비
+	s := &session{
+		w:        protocol.NewWriter(codec, conn, h.cfg.WriteTimeout),
+		local:    conn.LocalAddr().String(),
+		remote:   conn.RemoteAddr().String(),
+		peer:     peer,
+		inFlight: make(map[string]context.CancelCauseFunc),
+	}
+
+	// 연결이 끊기거나 강제 종료되면 처리중 요청 취소
+	ctx, cancel := context.WithCancel(h.base)
+	defer cancel()
+
+	// 연결당 동시 처리 제한
+	sem := make(chan struct{}, h.cfg.MaxInFlight)
+	var wg sync.WaitGroup
+
+	// hello는 첫 메시지로만 허용(생략 시 v1 클라이언트로 간주)
+	first := true
+
+	for {
+		// 다음 메시지 시작 대기(첫 메시지는 header 제한시간 유지)
+		if !first && !h.awaitMessage(conn, br, s) {
+			break
+		}
+
+		// 메시지 한 건 수신(크기 초과면 연결 종료)
+		msg, err := codec.ReadMessage()
+		if err != nil {
+			// 느린 전송은 단계별로 집계
+			if isTimeout(err) && !h.draining() {
+				if first {
+					h.cfg.OnTimeout(TimeoutHeader)
+				} else {
+					h.cfg.OnTimeout(TimeoutRead)
+				}
+			}
+			// 크기 초과는 알리고 종료(줄 경계를 믿을 수 없음)
+			if errors.Is(err, protocol.ErrFrameTooLarge) {
+				_ = s.w.WriteRes(protocol.Res{
+					Ok:        false,
+					Error:     err.Error(),
+					Code:      protocol.CodeFrameTooLarge,
+					TcpLocal:  s.local,
+					TcpRemote: s.remote,
+				})
+			}
+			break
+		}
+
+		// 요청 파싱
+		var req protocol.Req
+		if err := json.Unmarshal(msg.Header, &req); err != nil {
+			// 파싱 실패 응답(연결은 유지)
+			_ = s.w.WriteRes(protocol.Res{
+				Ok:        false,
+				Error:     "bad json",
+				Code:      protocol.CodeBadRequest,
+				TcpLocal:  s.local,
+				TcpRemote: s.remote,
+			})
+			continue
+		}
+
+		// 요청 원본 바이트(프레임 모드 write 청크)
+		req.Data = msg.Payload
+
+		// 버전 협상은 다른 요청보다 먼저 동기 처리
+		if req.Type == "hello" && first {
+			first = false
+			if !h.hello(s, req) {
+				break
+			}
+			continue
+		}
+		first = false
+
+		// 서명 검증(hello 제외)
+		if h.cfg.Verifier != nil {
+			if err := h.cfg.Verifier.Verify(msg.Header, msg.Payload); err != nil {
+				log.Printf("unauthorized request remote=%s subject=%q request_id=%s: %v", s.remote, s.peer.Subject, req.RequestID, err)
+				_ = s.w.WriteRes(protocol.Res{
+					Ok:        false,
+					Error:     "unauthorized: " + err.Error(),
+					Code:      protocol.CodeUnauthorized,
+					RequestID: req.RequestID,
+					UserID:    req.UserID,
+					TcpLocal:  s.local,
+					TcpRemote: s.remote,
+				})
+				continue
+			}
+		}
+
+		// 취소는 슬롯 없이 바로 처리(응답은 취소된 요청이 보냄)
+		if req.Type == "cancel" {
+			found := s.cancel(req.RequestID)
+			log.Printf("cancel request remote=%s subject=%q request_id=%s found=%v", s.remote, s.peer.Subject, req.RequestID, found)
+			continue
+		}
+
+		// ping도 슬롯 없이 바로 응답(바쁜 연결도 헬스체크 통과)
+		if req.Type == "ping" {
+			_ = s.w.WriteRes(protocol.Res{
+				Ok:        true,
+				Output:    "pong",
+				RequestID: req.RequestID,
+				UserID:    req.UserID,
+				TcpLocal:  s.local,
+				TcpRemote: s.remote,
+			})
+			continue
+		}
+
+		// 슬롯 확보(가득 차면 읽기는 계속하고 요청만 거절)
+		select {
+		case sem <- struct{}{}:
+		default:
+			h.rejectBusy(s, req)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			h.serve(ctx, s, req)
+		}()
+	}
+
+	// 응답을 못 쓰는 연결이면 처리중 요청도 중단
+	if err := s.w.Err(); err != nil {
+		if isTimeout(err) {
+			h.cfg.OnTimeout(TimeoutWrite)
+		}
+		cancel()
+	}
+
+	// 보낸 요청의 응답까지 전송 후 종료
+	wg.Wait()
+}
+
+// 다음 메시지 첫 바이트 대기(false면 연결 종료)
+// - 처리중 요청이 있으면 idle로 보지 않고 계속 대기
+// - 첫 바이트가 오면 메시지 끝까지 read 제한시간 적용
+func (h *Handler) awaitMessage(conn net.Conn, br *bufio.Reader, s *session) bool {
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(h.cfg.IdleTimeout))
+		// 종료 신호가 deadline을 덮어쓰지 않도록 설정 후 확인
+		if h.draining() {
+			return false
+		}
+
+		// Peek은 소비하지 않으므로 시간 초과 후 재시도해도 안전
+		if _, err := br.Peek(1); err != nil {
+			if !isTimeout(err) || h.draining() {
+				return false
+			}
+			if s.busy() {
+				continue
+			}
+			h.cfg.OnTimeout(TimeoutIdle)
+			return false
+		}
+
+		_ = conn.SetReadDeadline(time.Now().Add(h.cfg.ReadTimeout))
+		return !h.draining()
+	}
+}
+
+// 종료 중 여부
+func (h *Handler) draining() bool { return h.drain.Err() != nil }
+
+// 제한시간 초과 에러 여부
+func isTimeout(err error) bool {
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
+// 연결당 동시 처리 상한 초과 거절(읽기 루프에서 바로 응답)
+func (h *Handler) rejectBusy(s *session, req protocol.Req) {
+	if strings.TrimSpace(req.Type) == "" {
+		req.Type = "cmd"
+	}
+	log.Printf("request rejected remote=%s subject=%q request_id=%s: connection busy (%d in flight)", s.remote, s.peer.Subject, req.RequestID, h.cfg.MaxInFlight)
+	h.reject(s, req, protocol.Res{
+		Ok:        false,
+		Error:     fmt.Sprintf("too many requests in flight on this connection (max %d)", h.cfg.MaxInFlight),
+		Code:      protocol.CodeQueueFull,
+		RequestID: req.RequestID,
+		UserID:    req.UserID,
+		TcpLocal:  s.local,
+		TcpRemote: s.remote,
+	})
+}
+
+// 버전/기능 협상(실패면 false → 연결 종료)
+func (h *Handler) hello(s *session, req protocol.Req) bool {
+	res := protocol.Res{
+		RequestID: req.RequestID,
+		UserID:    req.UserID,
+		TcpLocal:  s.local,
+		TcpRemote: s.remote,
+		Version:   protocol.Version,
+		Caps:      requestTypes,
+		// 클라이언트가 연결별 동시 요청 수를 맞추도록 알림
+		MaxInFlight: h.cfg.MaxInFlight,
+		// 실행 대기 시간도 클라이언트 기한에 포함되도록 알림
+		QueueTimeoutMs: h.cfg.Exec.Pool.QueueTimeout().Milliseconds(),
+	}
+
+	// 모르는 버전 거절
+	if req.Version < protocol.MinVersion || req.Version > protocol.Version {
+		res.Ok = false
+		res.Error = fmt.Sprintf("unsupported protocol version %d (server supports %d..%d)",
+			req.Version, protocol.MinVersion, protocol.Version)
+		res.Code = protocol.CodeUnsupportedVersion
+		_ = s.w.WriteRes(res)
+		return false
+	}
+
+	res.Ok = true
+	return s.w.WriteRes(res) == nil
+}
+
+// 요청 한 건 처리
+func (h *Handler) serve(ctx context.Context, s *session, req protocol.Req) {
 	// user 기본값
 	if strings.TrimSpace(req.UserID) == "" {
 		req.UserID = "anonymous"
@@ -70,29 +480,144 @@ func (h *Handler) Handle(conn net.Conn) {
 	base := protocol.Res{
 		RequestID: req.RequestID,
 		UserID:    req.UserID,
-		TcpLocal:  local,
-		TcpRemote: remote,
+		TcpLocal:  s.local,
+		TcpRemote: s.remote,
+	}
+
+	// 같은 연결에서 같은 ID 동시 처리 금지
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	if !s.begin(req.RequestID, cancel) {
+		base.Ok = false
+		base.Error = "duplicate request_id in flight"
+		base.Code = protocol.CodeDuplicateRequestID
+		_ = s.w.WriteRes(base)
+		return
+	}
+	defer s.end(req.RequestID)
+
+	// 사용자 권한 검사(실행/파일 접근 전)
+	if err := authorize(req); err != nil {
+		log.Printf("authz denied remote=%s subject=%q request_id=%s user=%s type=%s: %v", s.remote, s.peer.Subject, req.RequestID, req.UserID, req.Type, err)
+		base.Ok = false
+		base.Error = err.Error()
+		base.Code = protocol.CodeForbidden
+		h.reject(s, req, base)
+		return
 	}
 
 	// 타입 분기
 	switch req.Type {
+	case "hello":
+		// 협상은 연결 첫 메시지에서만
+		base.Ok = false
+		base.Error = "hello must be the first message"
+		base.Code = protocol.CodeProtocolError
+		_ = s.w.WriteRes(base)
+
 	case "cmd":
+		// 스트리밍 실행 처리
+		if req.Stream {
+			h.runStream(ctx, s, req, base)
+			return
+		}
 		// cmd 실행 처리
-		res := execx.Run(req, base)
-		res.WriteLine(conn)
-		return
+		res := execx.Run(ctx, req, base, h.cfg.Exec)
+		_ = s.w.WriteRes(res)
 
 	case "file":
 		// 파일 읽기 처리
 		res := filex.ReadChunk(req, base)
-		res.WriteLine(conn)
-		return
+		_ = s.w.WriteRes(res)
+
+	case "list":
+		// 디렉터리 항목 조회
+		res := filex.List(req, base)
+		_ = s.w.WriteRes(res)
+
+	case "stat":
+		// 파일 정보 조회
+		res := filex.Stat(req, base)
+		_ = s.w.WriteRes(res)
+
+	case "hash":
+		// 파일 전체 해시(실행 풀 공유, cancel로 중단)
+		res := filex.Hash(ctx, req, base, h.cfg.Exec.Pool)
+		_ = s.w.WriteRes(res)
+
+	case "write":
+		// 업로드(open/append/commit/abort)
+		res := filex.Write(req, base)
+		_ = s.w.WriteRes(res)
+
+	case "policy":
+		// 적용중인 명령 정책 조회(관리용)
+		base.Ok = true
+		base.Policy = execx.CurrentPolicy().Info()
+		_ = s.w.WriteRes(base)
 
 	default:
 		// 미지원 타입 처리
 		base.Ok = false
 		base.Error = "unsupported type"
-		base.WriteLine(conn)
-		return
+		base.Code = protocol.CodeUnsupportedType
+		_ = s.w.WriteRes(base)
+	}
+}
+
+// 출력 조각을 이벤트로 보내며 실행
+func (h *Handler) runStream(ctx context.Context, s *session, req protocol.Req, base protocol.Res) {
+	// 전송 실패면 실행 중단
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	send := func(kind string, data []byte) {
+		ev := protocol.Event{Event: kind, RequestID: req.RequestID, Data: string(data)}
+		if err := s.w.WriteEvent(ev); err != nil {
+			cancel()
+		}
+	}
+
+	// 조각 끝에서 잘린 UTF-8 문자는 다음 조각까지 보류(stdout/stderr 따로)
+	var mu sync.Mutex
+	held := make(map[string][]byte)
+	emit := func(kind string, data []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		buf := append(held[kind], data...)
+		n := completeUTF8(buf)
+		held[kind] = bytes.Clone(buf[n:])
+		if n > 0 {
+			send(kind, buf[:n])
+		}
+	}
+
+	res := execx.RunStream(ctx, req, base, h.cfg.Exec, emit)
+
+	// 끝까지 완성되지 않은 바이트는 그대로 전송
+	for _, kind := range []string{protocol.EventStdout, protocol.EventStderr} {
+		if len(held[kind]) > 0 {
+			send(kind, held[kind])
+		}
+	}
+
+	// 출력은 이미 보냈으므로 결과에서 제외
+	res.Output, res.Stdout, res.Stderr = "", "", ""
+
+	_ = s.w.WriteEvent(protocol.Event{Event: protocol.EventExit, RequestID: req.RequestID, Result: &res})
+}
+
+// 끝의 미완성 UTF-8 문자를 뺀 길이(잘못된 바이트는 완성된 것으로 취급)
+func completeUTF8(b []byte) int {
+	for i := 1; i < utf8.UTFMax && i <= len(b); i++ {
+		start := len(b) - i
+		if !utf8.RuneStart(b[start]) {
+			continue
+		}
+		if utf8.FullRune(b[start:]) {
+			return len(b)
+		}
+		return start
 	}
+	return len(b)
 }
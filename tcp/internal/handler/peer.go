@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"crypto/tls"
+	"net"
+)
+
+// 연결 상대 정보
+type Peer struct {
+	// TLS 여부
+	TLS bool
+	// 클라이언트 인증서 주체(mTLS)
+	Subject string
+	// 클라이언트 인증서 CN
+	CommonName string
+}
+
+// 평문이면 빈 Peer, TLS면 핸드셰이크 후 인증서 주체
+func peerOf(conn net.Conn) (Peer, error) {
+	tc, ok := conn.(*tls.Conn)
+	if !ok {
+		return Peer{}, nil
+	}
+
+	// 핸드셰이크 완료 후 인증서 확인(제한시간은 연결 deadline)
+	if err := tc.Handshake(); err != nil {
+		return Peer{}, err
+	}
+
+	p := Peer{TLS: true}
+	if certs := tc.ConnectionState().PeerCertificates; len(certs) > 0 {
+		p.Subject = certs[0].Subject.String()
+		p.CommonName = certs[0].Subject.CommonName
+	}
+	return p, nil
+}
+
+// 허용 CN 검사(목록이 비면 모두 허용)
+func (h *Handler) peerAllowed(p Peer) bool {
+	if len(h.allowed) == 0 {
+		return true
+	}
+	return h.allowed[p.CommonName]
+}
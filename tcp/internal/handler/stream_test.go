@@ -0,0 +1,29 @@
+package handler
+
+import "testing"
+
+func TestCompleteUTF8(t *testing.T) {
+	// "한" = ed 95 9c, "😀" = f0 9f 98 80
+	cases := []struct {
+		name string
+		in   string
+		want int
+	}{
+		{"empty", "", 0},
+		{"ascii", "abc", 3},
+		{"full rune", "a한", 4},
+		{"cut after 1 of 3", "a\xed", 1},
+		{"cut after 2 of 3", "a\xed\x95", 1},
+		{"cut after 3 of 4", "a\xf0\x9f\x98", 1},
+		{"full 4 byte rune", "a\xf0\x9f\x98\x80", 5},
+		{"invalid byte is not held", "a\xff", 2},
+		{"stray continuation", "a\x80\x80\x80", 4},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := completeUTF8([]byte(tc.in)); got != tc.want {
+				t.Fatalf("completeUTF8(%q) = %d, want %d", tc.in, got, tc.want)
+			}
+		})
+	}
+}
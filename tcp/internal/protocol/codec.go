@@ -0,0 +1,154 @@
+package protocol
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// 프레임 모드 핸드셰이크 바이트(JSON 줄은 '{'로 시작하므로 겹치지 않음)
+const FrameMagic byte = 0xF7
+
+// 메시지 최대 크기 기본값(헤더 + payload)
+const DefaultMaxFrame = 4 << 20
+
+// 프레임 앞 길이 필드 크기(헤더 길이 4 + payload 길이 4)
+const frameHeaderSize = 8
+
+// 크기 초과 에러
+var ErrFrameTooLarge = errors.New("frame too large")
+
+// 줄 모드에서 payload 전송 시도
+var ErrBinaryUnsupported = errors.New("binary payload needs frame mode")
+
+// 메시지 한 건(JSON 헤더 + 선택적 바이너리)
+type Message struct {
+	// JSON 문서
+	Header []byte
+	// 원본 바이트(프레임 모드 전용)
+	Payload []byte
+}
+
+// 연결 단위 직렬화 방식
+type Codec interface {
+	// 메시지 한 건 읽기(읽기 고루틴 하나에서만 호출)
+	ReadMessage() (Message, error)
+	// 메시지 한 건 쓰기(Writer가 직렬화)
+	WriteMessage(Message) error
+	// payload 지원 여부
+	Binary() bool
+}
+
+// 서버: 첫 바이트로 모드 결정
+// - FrameMagic이면 소비 후 같은 바이트로 응답하고 프레임 모드
+// - 그 외는 기존 JSON 줄 모드
+func ServerCodec(rw io.ReadWriter, br *bufio.Reader, max int) (Codec, error) {
+	if max <= 0 {
+		max = DefaultMaxFrame
+	}
+
+	// 첫 바이트 확인
+	b, err := br.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	if b[0] != FrameMagic {
+		return &lineCodec{r: br, w: rw, max: max}, nil
+	}
+
+	// 핸드셰이크 소비 + 응답
+	_, _ = br.ReadByte()
+	if _, err := rw.Write([]byte{FrameMagic}); err != nil {
+		return nil, err
+	}
+	return &frameCodec{r: br, w: rw, max: max}, nil
+}
+
+// JSON 한 줄 = 메시지 한 건
+type lineCodec struct {
+	r   *bufio.Reader
+	w   io.Writer
+	max int
+}
+
+// 크기 제한 있는 한 줄 읽기
+func (c *lineCodec) ReadMessage() (Message, error) {
+	var line []byte
+	for {
+		chunk, err := c.r.ReadSlice('\n')
+		// 끝없는 줄 방지
+		if len(line)+len(chunk) > c.max+1 {
+			return Message{}, ErrFrameTooLarge
+		}
+		line = append(line, chunk...)
+		if err == nil {
+			return Message{Header: line[:len(line)-1]}, nil
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return Message{}, err
+		}
+	}
+}
+
+// JSON + '\n' 한 줄 쓰기
+func (c *lineCodec) WriteMessage(m Message) error {
+	if len(m.Payload) > 0 {
+		return ErrBinaryUnsupported
+	}
+	if len(m.Header) > c.max {
+		return ErrFrameTooLarge
+	}
+	_, err := c.w.Write(append(m.Header, '\n'))
+	return err
+}
+
+func (c *lineCodec) Binary() bool { return false }
+
+// [헤더 길이 u32][payload 길이 u32][JSON 헤더][payload]
+type frameCodec struct {
+	r   io.Reader
+	w   io.Writer
+	max int
+}
+
+// 프레임 한 건 읽기
+func (c *frameCodec) ReadMessage() (Message, error) {
+	var lens [frameHeaderSize]byte
+	if _, err := io.ReadFull(c.r, lens[:]); err != nil {
+		return Message{}, err
+	}
+	hl := binary.BigEndian.Uint32(lens[0:4])
+	pl := binary.BigEndian.Uint32(lens[4:8])
+
+	// 할당 전에 크기 검사
+	if uint64(hl)+uint64(pl) > uint64(c.max) {
+		return Message{}, ErrFrameTooLarge
+	}
+
+	buf := make([]byte, int(hl)+int(pl))
+	if _, err := io.ReadFull(c.r, buf); err != nil {
+		return Message{}, err
+	}
+	m := Message{Header: buf[:hl]}
+	if pl > 0 {
+		m.Payload = buf[hl:]
+	}
+	return m, nil
+}
+
+// 프레임 한 건 쓰기(한 번의 Write로 전송)
+func (c *frameCodec) WriteMessage(m Message) error {
+	if len(m.Header)+len(m.Payload) > c.max {
+		return ErrFrameTooLarge
+	}
+	buf := make([]byte, frameHeaderSize, frameHeaderSize+len(m.Header)+len(m.Payload))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(m.Header)))
+	binary.BigEndian.PutUint32(buf[4:8], uint32(len(m.Payload)))
+	buf = append(buf, m.Header...)
+	buf = append(buf, m.Payload...)
+	_, err := c.w.Write(buf)
+	return err
+}
+
+func (c *frameCodec) Binary() bool { return true }
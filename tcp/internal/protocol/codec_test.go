@@ -0,0 +1,175 @@
+package protocol
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// 고정 바이트 케이스(클라이언트 tcpclient/frame_test.go와 같은 값)
+const goldHeader = `{"request_id":"a1","ok":true}`
+
+// 29바이트 헤더 + 5바이트 페이로드
+const goldFrame = "\x00\x00\x00\x1d\x00\x00\x00\x05" + goldHeader + "\x00\x01\xf7\xff\n"
+
+var codecCases = []struct {
+	name    string
+	mode    string
+	max     int
+	wire    string
+	header  string
+	payload string
+	err     string
+}{
+	{name: "line message", mode: "line", max: 64, wire: goldHeader + "\n", header: goldHeader},
+	{name: "line at limit", mode: "line", max: 29, wire: goldHeader + "\n", header: goldHeader},
+	{name: "line over limit", mode: "line", max: 28, wire: goldHeader + "\n", err: "too_large"},
+	{name: "line over limit past reader buffer", mode: "line", max: 4200, wire: `{"request_id":"a1","pad":"` + strings.Repeat("x", 5000) + `"}` + "\n", err: "too_large"},
+	{name: "line truncated", mode: "line", max: 64, wire: goldHeader, err: "eof"},
+	{name: "frame header only", mode: "frame", max: 64, wire: "\x00\x00\x00\x1d\x00\x00\x00\x00" + goldHeader, header: goldHeader},
+	{name: "frame with payload", mode: "frame", max: 64, wire: goldFrame, header: goldHeader, payload: "\x00\x01\xf7\xff\n"},
+	{name: "frame at limit", mode: "frame", max: 34, wire: goldFrame, header: goldHeader, payload: "\x00\x01\xf7\xff\n"},
+	{name: "frame over limit", mode: "frame", max: 33, wire: goldFrame, err: "too_large"},
+	{name: "frame huge lengths rejected before body", mode: "frame", max: 64, wire: "\xff\xff\xff\xf0\xff\xff\xff\xf0", err: "too_large"},
+	{name: "frame payload length alone over limit", mode: "frame", max: 64, wire: "\x00\x00\x00\x02\x00\x3f\xff\xff{}", err: "too_large"},
+	{name: "frame truncated body", mode: "frame", max: 64, wire: goldFrame[:40], err: "eof"},
+}
+
+// 읽기 전용 입력 + 쓰기 기록
+type pipeRW struct {
+	in  io.Reader
+	out bytes.Buffer
+}
+
+func (p *pipeRW) Read(b []byte) (int, error)  { return p.in.Read(b) }
+func (p *pipeRW) Write(b []byte) (int, error) { return p.out.Write(b) }
+
+func TestFrameMagic(t *testing.T) {
+	// 클라이언트와 약속한 값
+	if FrameMagic != 0xf7 {
+		t.Fatalf("FrameMagic = %#x, want 0xf7", FrameMagic)
+	}
+}
+
+func TestServerCodecHandshake(t *testing.T) {
+	// 매직 바이트면 소비 후 같은 바이트로 응답하고 프레임 모드
+	rw := &pipeRW{in: bytes.NewReader([]byte{FrameMagic, 0, 0, 0, 2, 0, 0, 0, 0, '{', '}'})}
+	c, err := ServerCodec(rw, bufio.NewReader(rw.in), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Binary() {
+		t.Fatal("magic byte did not select frame mode")
+	}
+	if !bytes.Equal(rw.out.Bytes(), []byte{FrameMagic}) {
+		t.Fatalf("handshake reply = %x, want %x", rw.out.Bytes(), FrameMagic)
+	}
+	m, err := c.ReadMessage()
+	if err != nil || string(m.Header) != "{}" {
+		t.Fatalf("first frame = %q, %v", m.Header, err)
+	}
+
+	// '{'로 시작하면 아무것도 쓰지 않고 줄 모드(첫 바이트는 소비하지 않음)
+	rw = &pipeRW{in: bytes.NewReader([]byte("{}\n"))}
+	c, err = ServerCodec(rw, bufio.NewReader(rw.in), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Binary() || rw.out.Len() != 0 {
+		t.Fatalf("line mode: binary=%v wrote %x", c.Binary(), rw.out.Bytes())
+	}
+	if m, err := c.ReadMessage(); err != nil || string(m.Header) != "{}" {
+		t.Fatalf("first line = %q, %v", m.Header, err)
+	}
+}
+
+func TestCodecCases(t *testing.T) {
+	for _, tc := range codecCases {
+		t.Run(tc.name, func(t *testing.T) {
+			wire := []byte(tc.wire)
+			in := wire
+			if tc.mode == "frame" {
+				in = append([]byte{FrameMagic}, wire...)
+			}
+			rw := &pipeRW{in: bytes.NewReader(in)}
+			c, err := ServerCodec(rw, bufio.NewReader(rw.in), tc.max)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Binary() != (tc.mode == "frame") {
+				t.Fatalf("mode = binary %v, want %s", c.Binary(), tc.mode)
+			}
+
+			// 읽기
+			m, err := c.ReadMessage()
+			switch tc.err {
+			case "too_large":
+				if !errors.Is(err, ErrFrameTooLarge) {
+					t.Fatalf("err = %v, want %v", err, ErrFrameTooLarge)
+				}
+				return
+			case "eof":
+				if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+					t.Fatalf("err = %v, want EOF", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(m.Header) != tc.header || !bytes.Equal(m.Payload, []byte(tc.payload)) {
+				t.Fatalf("read %q %x, want %q %x", m.Header, m.Payload, tc.header, tc.payload)
+			}
+
+			// 쓰기는 같은 바이트를 만들어야 함
+			var out bytes.Buffer
+			wc := Codec(&lineCodec{w: &out, max: tc.max})
+			if tc.mode == "frame" {
+				wc = &frameCodec{w: &out, max: tc.max}
+			}
+			if err := wc.WriteMessage(Message{Header: m.Header, Payload: m.Payload}); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(out.Bytes(), wire) {
+				t.Fatalf("write = %x, want %x", out.Bytes(), wire)
+			}
+		})
+	}
+}
+
+func TestCodecWriteLimits(t *testing.T) {
+	var out bytes.Buffer
+
+	// 쓰기도 같은 상한 적용
+	if err := (&frameCodec{w: &out, max: 4}).WriteMessage(Message{Header: []byte("{}"), Payload: []byte("abc")}); !errors.Is(err, ErrFrameTooLarge) {
+		t.Fatalf("frame write err = %v", err)
+	}
+	if err := (&lineCodec{w: &out, max: 1}).WriteMessage(Message{Header: []byte("{}")}); !errors.Is(err, ErrFrameTooLarge) {
+		t.Fatalf("line write err = %v", err)
+	}
+	// 줄 모드는 바이너리 불가
+	if err := (&lineCodec{w: &out, max: 64}).WriteMessage(Message{Header: []byte("{}"), Payload: []byte{1}}); !errors.Is(err, ErrBinaryUnsupported) {
+		t.Fatalf("line payload err = %v", err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("rejected writes wrote %x", out.Bytes())
+	}
+}
+
+// 선언된 길이가 커도 본문을 읽거나 할당하기 전에 거절
+func TestFrameSizeCheckedBeforeAlloc(t *testing.T) {
+	hdr := []byte{0xff, 0xff, 0xff, 0xf0, 0xff, 0xff, 0xff, 0xf0}
+	allocs := testing.AllocsPerRun(10, func() {
+		c := &frameCodec{r: bytes.NewReader(hdr), max: DefaultMaxFrame}
+		if _, err := c.ReadMessage(); !errors.Is(err, ErrFrameTooLarge) {
+			t.Fatalf("err = %v", err)
+		}
+	})
+	// 코덱/리더 구조체 외 본문 버퍼 할당 없음
+	if allocs > 3 {
+		t.Fatalf("allocs = %v", allocs)
+	}
+}
@@ -1,8 +1,15 @@
 package protocol
 
 import (
-	"encoding/json"
-	"net"
+	"time"
+)
+
+// 프로토콜 버전
+const (
+	// 서버가 말하는 버전
+	Version = 1
+	// 받아들이는 최소 버전
+	MinVersion = 1
 )
 
 // 요청 스키마
@@ -11,26 +18,72 @@ type Req struct {
 	RequestID string `json:"request_id"`
 	// 사용자 ID
 	UserID string `json:"user_id"`
-	// 작업 타입(cmd/file)
+	// 작업 타입(hello/ping/cmd/file/list/stat/hash/write/cancel/policy)
+	// - list/stat: 디렉터리 항목/파일 정보 조회(path가 비면 기본 루트)
+	// - hash: 파일 전체의 SHA-256/CRC32C(timeout_ms로 제한 가능)
+	// - write: 쓰기 가능한 루트에 업로드(op: open → append... → commit, 또는 abort)
+	// - cancel: request_id가 같은 처리중 요청을 취소(응답은 원래 요청이 보냄)
+	// - policy: 적용중인 명령 정책 조회(관리용)
 	Type string `json:"type"`
 
+	// hello: 클라이언트 프로토콜 버전
+	Version int `json:"version,omitempty"`
+	// hello: 클라이언트가 쓰려는 요청 타입
+	Caps []string `json:"caps,omitempty"`
+
 	// cmd 실행
 	Cmd string `json:"cmd"`
+	// 실행 제한시간(ms, 0이면 서버 기본값, hash는 0이면 무제한)
+	TimeoutMs int64 `json:"timeout_ms"`
+	// 출력 스트리밍 여부
+	Stream bool `json:"stream"`
 
-	// 파일 읽기
+	// 파일 읽기(list는 건너뛸 항목 수/최대 항목 수)
 	Path   string `json:"path"`
 	Offset int64  `json:"offset"`
 	Limit  int64  `json:"limit"`
+	// list: 이름 glob 필터("*.log")
+	Pattern string `json:"pattern,omitempty"`
+
+	// write: 단계(open/append/commit/abort)
+	Op string `json:"op,omitempty"`
+	// write: open 응답으로 받은 업로드 ID
+	UploadID string `json:"upload_id,omitempty"`
+	// write: commit 시 검증할 전체 SHA-256(hex)
+	SHA256 string `json:"sha256,omitempty"`
+	// write: 청크(Base64, 줄 모드)
+	DataB64 string `json:"data_b64,omitempty"`
+	// write: 청크 원본(프레임 모드는 payload로 수신)
+	Data []byte `json:"-"`
 }
 
 // 응답 스키마
 type Res struct {
 	// 성공 여부
 	Ok bool `json:"ok"`
-	// 출력 텍스트
+	// 출력 텍스트(stdout+stderr, 하위 호환)
 	Output string `json:"output"`
+	// 표준 출력
+	Stdout string `json:"stdout,omitempty"`
+	// 표준 에러
+	Stderr string `json:"stderr,omitempty"`
 	// 에러 메시지
 	Error string `json:"error"`
+	// 실패 사유(exit/timeout/killed)
+	Reason string `json:"reason,omitempty"`
+	// 에러 코드(기계 판별용)
+	Code string `json:"code,omitempty"`
+	// 출력 잘림 여부
+	Truncated bool `json:"truncated,omitempty"`
+	// 종료 코드(cmd 응답만, -1이면 정상 종료 아님)
+	ExitCode *int `json:"exit_code,omitempty"`
+	// 실행 시작 시각
+	StartedAt time.Time `json:"started_at,omitzero"`
+	// 실행 시간(ms)
+	DurationMs int64 `json:"duration_ms"`
+	// 실행 대기열: 들어갈 때 앞의 대기 수 / 기다린 시간(ms)
+	QueueDepth  int   `json:"queue_depth,omitempty"`
+	QueueWaitMs int64 `json:"queue_wait_ms,omitempty"`
 
 	// 추적용 ID
 	RequestID string `json:"request_id"`
@@ -41,18 +94,177 @@ type Res struct {
 	TcpLocal  string `json:"tcp_local"`
 	TcpRemote string `json:"tcp_remote"`
 
-	// 파일 청크(Base64)
+	// hello: 합의된 프로토콜 버전
+	Version int `json:"version,omitempty"`
+	// hello: 서버가 지원하는 요청 타입
+	Caps []string `json:"caps,omitempty"`
+	// hello: 연결당 동시 처리 요청 수(초과분은 QUEUE_FULL)
+	MaxInFlight int `json:"max_in_flight,omitempty"`
+	// hello: 실행 슬롯 최대 대기 시간(클라이언트 응답 기한에 더함)
+	QueueTimeoutMs int64 `json:"queue_timeout_ms,omitempty"`
+
+	// 파일 청크(Base64, 줄 모드)
 	FileB64 string `json:"file_b64"`
-	// 다음 오프셋
+	// 파일 청크 원본(프레임 모드는 payload로 전송)
+	Data []byte `json:"-"`
+	// 다음 오프셋(list는 다음 페이지 시작 항목)
 	NextOffset int64 `json:"next_offset"`
-	// EOF 여부
+	// EOF 여부(file은 파일 크기 기준, list는 마지막 페이지)
 	EOF bool `json:"eof"`
+	// file/hash: 읽은 시점의 파일 크기(진행률용)
+	FileSize int64 `json:"file_size"`
+	// file: 청크의, hash: 파일 전체의 체크섬(hex, CRC32C는 Castagnoli 8자리)
+	CRC32C string `json:"crc32c,omitempty"`
+	SHA256 string `json:"sha256,omitempty"`
+
+	// list: 디렉터리 항목(이름순)
+	Entries []FileEntry `json:"entries,omitempty"`
+	// stat: 파일 정보(hash는 계산한 파일, write commit은 저장된 파일)
+	Entry *FileEntry `json:"entry,omitempty"`
+
+	// write: 업로드 ID(open 응답)
+	UploadID string `json:"upload_id,omitempty"`
+	// write: commit으로 새 파일이 생김(false면 덮어씀)
+	Created bool `json:"created,omitempty"`
+
+	// policy: 적용중인 명령 정책
+	Policy *PolicyInfo `json:"policy,omitempty"`
 }
 
-// 응답 한 줄 전송
-func (r Res) WriteLine(conn net.Conn) {
-	// JSON 직렬화
-	b, _ := json.Marshal(r)
-	// 한 줄 프로토콜
-	_, _ = conn.Write(append(b, '\n'))
+// 파일 항목 종류
+const (
+	EntryFile    = "file"
+	EntryDir     = "dir"
+	EntrySymlink = "symlink"
+	EntryOther   = "other"
+)
+
+// list/stat 항목
+type FileEntry struct {
+	// 파일 이름
+	Name string `json:"name"`
+	// 요청에 다시 쓸 수 있는 경로("/a/b", "logs:/a/b")
+	Path string `json:"path"`
+	// 종류(file/dir/symlink/other)
+	Type string `json:"type"`
+	// 크기(byte)
+	Size int64 `json:"size"`
+	// 권한("0644")
+	Mode string `json:"mode"`
+	// 수정 시각
+	ModTime time.Time `json:"mtime"`
+}
+
+// 명령 하나의 인자 정책(정책 파일/policy 응답 공통)
+type CommandPolicy struct {
+	// 허용 플래그("-l", "--all" 형태)
+	Flags []string `json:"flags,omitempty" yaml:"flags"`
+	// 최대 인자 수(플래그 포함)
+	MaxArgs int `json:"max_args" yaml:"max_args"`
+	// 일반 인자 허용 정규식(비면 일반 인자 금지)
+	ArgPattern string `json:"arg_pattern,omitempty" yaml:"arg_pattern"`
+	// 일반 인자를 루트 하위 경로로 강제
+	PathArgs bool `json:"path_args,omitempty" yaml:"path_args"`
+}
+
+// 적용중인 명령 정책
+type PolicyInfo struct {
+	// 정책 내용 해시("sha256:...")
+	Version string `json:"version"`
+	// 정책 출처(파일 경로, 내장이면 "builtin")
+	Source string `json:"source"`
+	// 적용 시각
+	LoadedAt time.Time `json:"loaded_at"`
+	// 명령 → 정책
+	Commands map[string]CommandPolicy `json:"commands"`
+}
+
+// 실패 사유
+const (
+	// 0이 아닌 종료 코드
+	ReasonExit = "exit"
+	// 제한시간 초과로 강제 종료
+	ReasonTimeout = "timeout"
+	// 시그널로 종료
+	ReasonKilled = "killed"
+	// 클라이언트 cancel 요청으로 종료
+	ReasonCancelled = "cancelled"
+)
+
+// 에러 코드(Error 문구가 바뀌어도 값은 유지)
+const (
+	// 잘못된 JSON, 필수 값 누락
+	CodeBadRequest = "BAD_REQUEST"
+	// 모르는 요청 타입
+	CodeUnsupportedType = "UNSUPPORTED_TYPE"
+	// 지원하지 않는 프로토콜 버전
+	CodeUnsupportedVersion = "UNSUPPORTED_VERSION"
+	// 메시지 순서 위반(hello가 첫 메시지가 아님 등)
+	CodeProtocolError = "PROTOCOL_ERROR"
+	// 같은 연결에서 처리중인 request_id 재사용
+	CodeDuplicateRequestID = "DUPLICATE_REQUEST_ID"
+	// 메시지 크기 초과
+	CodeFrameTooLarge = "FRAME_TOO_LARGE"
+	// 서명 검증 실패
+	CodeUnauthorized = "UNAUTHORIZED"
+	// 사용자 역할에 허용되지 않은 요청 타입/명령/경로
+	CodeForbidden = "FORBIDDEN"
+
+	// allowlist에 없는 명령
+	CodeCmdNotAllowed = "CMD_NOT_ALLOWED"
+	// 플래그/인자 정책 위반
+	CodeArgNotAllowed = "ARG_NOT_ALLOWED"
+	// 루트 밖 경로
+	CodePathEscape = "PATH_ESCAPE"
+	// 파일 없음
+	CodeNotFound = "NOT_FOUND"
+	// 파일 권한 없음
+	CodePermissionDenied = "PERMISSION_DENIED"
+	// 그 밖의 파일 입출력 실패
+	CodeIOError = "IO_ERROR"
+	// 읽기 전용 루트에 쓰기
+	CodeReadOnly = "READ_ONLY"
+	// 업로드 청크 오프셋이 받은 크기와 다름(next_offset에 현재 크기)
+	CodeOffsetMismatch = "OFFSET_MISMATCH"
+	// 업로드 크기 상한 초과
+	CodeFileTooLarge = "FILE_TOO_LARGE"
+	// commit SHA-256 불일치(임시 파일 삭제)
+	CodeChecksumMismatch = "CHECKSUM_MISMATCH"
+
+	// 0이 아닌 종료 코드
+	CodeExitNonZero = "EXIT_NONZERO"
+	// 실행 제한시간 초과
+	CodeTimeout = "TIMEOUT"
+	// 시그널로 종료
+	CodeKilled = "KILLED"
+	// 클라이언트 cancel 요청으로 중단
+	CodeCancelled = "CANCELLED"
+	// 실행 대기열이 가득 참
+	CodeQueueFull = "QUEUE_FULL"
+	// 실행 슬롯 대기 시간 초과
+	CodeQueueTimeout = "QUEUE_TIMEOUT"
+	// 그 밖의 서버 오류
+	CodeInternal = "INTERNAL"
+)
+
+// 스트림 이벤트 종류
+const (
+	// 표준 출력 조각
+	EventStdout = "stdout"
+	// 표준 에러 조각
+	EventStderr = "stderr"
+	// 실행 종료(최종 결과 포함)
+	EventExit = "exit"
+)
+
+// 스트림 이벤트 스키마(stream 요청은 응답 대신 이벤트 여러 줄)
+type Event struct {
+	// 이벤트 종류
+	Event string `json:"event"`
+	// 추적용 ID
+	RequestID string `json:"request_id"`
+	// 출력 조각(stdout/stderr)
+	Data string `json:"data,omitempty"`
+	// 최종 결과(exit, 출력 필드는 비움)
+	Result *Res `json:"result,omitempty"`
 }
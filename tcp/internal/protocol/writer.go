@@ -0,0 +1,80 @@
+package protocol
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+)
+
+// 한 연결에 여러 요청 응답이 섞이므로 메시지 단위 쓰기를 직렬화
+type Writer struct {
+	mu sync.Mutex
+	c  Codec
+	// 쓰기 제한시간 대상 연결
+	conn net.Conn
+	// 메시지 한 건 쓰기 제한시간(0이면 제한 없음)
+	timeout time.Duration
+	// 첫 쓰기 실패(이후 쓰기는 바로 실패)
+	err error
+}
+
+// writer 생성
+func NewWriter(c Codec, conn net.Conn, timeout time.Duration) *Writer {
+	return &Writer{c: c, conn: conn, timeout: timeout}
+}
+
+// 첫 쓰기 실패 원인(없으면 nil)
+func (w *Writer) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// 응답 한 건 전송
+// - 프레임 모드면 Data를 payload로, 줄 모드면 file_b64로 전송
+func (w *Writer) WriteRes(r Res) error {
+	var payload []byte
+	if len(r.Data) > 0 {
+		if w.c.Binary() {
+			payload = r.Data
+		} else {
+			r.FileB64 = base64.StdEncoding.EncodeToString(r.Data)
+		}
+	}
+	return w.write(r, payload)
+}
+
+// 이벤트 한 건 전송
+func (w *Writer) WriteEvent(e Event) error {
+	return w.write(e, nil)
+}
+
+// JSON 헤더 + payload 전송
+func (w *Writer) write(v any, payload []byte) error {
+	// JSON 직렬화
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	// 메시지가 섞이지 않게 잠금
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+
+	// 읽지 않는 클라이언트가 잠금을 붙잡지 않게 메시지마다 제한시간
+	if w.timeout > 0 {
+		_ = w.conn.SetWriteDeadline(time.Now().Add(w.timeout))
+	}
+	if err := w.c.WriteMessage(Message{Header: b, Payload: payload}); err != nil {
+		// 일부만 쓰였을 수 있어 메시지 경계를 믿을 수 없음 → 연결 종료
+		w.err = err
+		_ = w.conn.Close()
+		return err
+	}
+	return nil
+}
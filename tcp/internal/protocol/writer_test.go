@@ -0,0 +1,67 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+// 응답을 읽지 않는 클라이언트는 제한시간 후 실패 + 연결 종료
+func TestWriterTimeout(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+
+	w := NewWriter(&lineCodec{w: server, max: DefaultMaxFrame}, server, 50*time.Millisecond)
+
+	start := time.Now()
+	err := w.WriteRes(Res{RequestID: "r1", Ok: true})
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Fatalf("write blocked %v", d)
+	}
+	if !errors.Is(w.Err(), os.ErrDeadlineExceeded) {
+		t.Fatalf("Err() = %v", w.Err())
+	}
+
+	// 이후 쓰기는 기다리지 않고 같은 에러
+	if err := w.WriteEvent(Event{Event: EventExit, RequestID: "r2"}); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("second write err = %v", err)
+	}
+
+	// 연결은 닫혀 상대도 종료를 봄
+	if _, err := client.Read(make([]byte, 1)); !errors.Is(err, io.EOF) {
+		t.Fatalf("peer read err = %v, want EOF", err)
+	}
+}
+
+// 제한시간 안에 읽히면 정상 전송
+func TestWriterWithinTimeout(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+
+	w := NewWriter(&lineCodec{w: server, max: DefaultMaxFrame}, server, time.Second)
+	go func() { _ = w.WriteRes(Res{RequestID: "r1", Ok: true}) }()
+
+	line := make([]byte, 0, 256)
+	buf := make([]byte, 256)
+	for !bytes.HasSuffix(line, []byte("\n")) {
+		n, err := client.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		line = append(line, buf[:n]...)
+	}
+	if !bytes.Contains(line, []byte(`"request_id":"r1"`)) {
+		t.Fatalf("line = %s", line)
+	}
+	if err := w.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+}
@@ -1,8 +1,16 @@
 package server
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log"
 	"net"
+	"sync"
+	"time"
 
+	"golang-network-labs/tcp/internal/auth"
+	"golang-network-labs/tcp/internal/execx"
 	"golang-network-labs/tcp/internal/handler"
 )
 
@@ -10,39 +18,283 @@ import (
 type Config struct {
 	// 리슨 주소
 	Addr string
+	// 명령 실행 제한
+	Exec execx.Options
+	// 메시지 최대 크기
+	MaxFrame int
+	// TLS/mTLS
+	TLS TLSConfig
+	// 요청 서명(키가 없으면 검사 안 함)
+	Auth auth.Config
+
+	// 동시 연결 수 상한
+	MaxConns int
+	// IP당 동시 연결 수 상한
+	MaxConnsPerIP int
+	// 연결 후 첫 메시지까지(TLS 핸드셰이크 포함)
+	HeaderTimeout time.Duration
+	// 메시지 시작 후 끝까지
+	ReadTimeout time.Duration
+	// 처리중 요청 없이 다음 메시지까지
+	IdleTimeout time.Duration
+	// 응답 메시지 한 건 쓰기
+	WriteTimeout time.Duration
 }
 
+// 연결 제한 기본값
+const (
+	DefaultMaxConns      = 1024
+	DefaultMaxConnsPerIP = 64
+)
+
+// Shutdown 이후 ListenAndServe 반환값
+var ErrServerClosed = errors.New("tcp: server closed")
+
+// Accept 일시 오류 재시도 대기 상한
+const maxAcceptDelay = time.Second
+
+// 강제 취소 후 취소 결과를 보낼 여유
+const abortGrace = 2 * time.Second
+
 // 서버 본체
 type Server struct {
 	// 설정 보관
 	cfg Config
 	// 핸들러 보관
 	h *handler.Handler
+
+	// 리스너 + 열린 연결
+	mu      sync.Mutex
+	ln      net.Listener
+	conns   map[net.Conn]struct{}
+	perIP   map[string]int
+	closing bool
+	// 거절/시간 초과 집계
+	stats counters
+	// Handle 고루틴 수
+	wg sync.WaitGroup
 }
 
 // 서버 생성
 func New(cfg Config) *Server {
+	// 기본값 보정
+	if cfg.MaxConns <= 0 {
+		cfg.MaxConns = DefaultMaxConns
+	}
+	if cfg.MaxConnsPerIP <= 0 {
+		cfg.MaxConnsPerIP = DefaultMaxConnsPerIP
+	}
+
+	// 서버 전체 실행 풀(집계에서도 사용)
+	if cfg.Exec.Pool == nil {
+		cfg.Exec.Pool = execx.NewPool(cfg.Exec)
+	}
+
+	s := &Server{
+		cfg:   cfg,
+		conns: make(map[net.Conn]struct{}),
+		perIP: make(map[string]int),
+	}
+
 	// 핸들러 생성
-	h := handler.New()
+	s.h = handler.New(handler.Config{
+		Exec:           cfg.Exec,
+		MaxFrame:       cfg.MaxFrame,
+		AllowedClients: cfg.TLS.AllowedClients,
+		Verifier:       auth.New(cfg.Auth),
+		HeaderTimeout:  cfg.HeaderTimeout,
+		ReadTimeout:    cfg.ReadTimeout,
+		IdleTimeout:    cfg.IdleTimeout,
+		WriteTimeout:   cfg.WriteTimeout,
+		OnTimeout:      s.stats.timedOut,
+	})
 	// 서버 반환
-	return &Server{cfg: cfg, h: h}
+	return s
 }
 
-// TCP 리슨 + Accept 루프
+// TCP 리슨 + Accept 루프(Shutdown 후에는 ErrServerClosed)
 func (s *Server) ListenAndServe() error {
 	// 리슨 시작
 	ln, err := net.Listen("tcp", s.cfg.Addr)
 	if err != nil {
 		return err
 	}
+
+	// TLS면 리스너 감싸기
+	if s.cfg.TLS.Enabled() {
+		tc, err := s.cfg.TLS.build()
+		if err != nil {
+			_ = ln.Close()
+			return err
+		}
+		ln = tls.NewListener(ln, tc)
+	}
+
+	// 종료 중이면 바로 반환
+	s.mu.Lock()
+	if s.closing {
+		s.mu.Unlock()
+		_ = ln.Close()
+		return ErrServerClosed
+	}
+	s.ln = ln
+	s.mu.Unlock()
+
+	// Accept 일시 오류 재시도 대기
+	var delay time.Duration
+
 	// 계속 연결 처리
 	for {
 		// 연결 수락
 		conn, err := ln.Accept()
 		if err != nil {
+			// Shutdown으로 닫힘
+			if s.shuttingDown() {
+				return ErrServerClosed
+			}
+			// 리스너 자체가 닫히면 종료
+			if errors.Is(err, net.ErrClosed) {
+				return err
+			}
+			// fd 부족 등 일시 오류는 점점 늘려 재시도
+			if delay == 0 {
+				delay = 5 * time.Millisecond
+			} else {
+				delay = min(2*delay, maxAcceptDelay)
+			}
+			log.Printf("accept error: %v; retrying in %v", err, delay)
+			time.Sleep(delay)
 			continue
 		}
+		delay = 0
+
+		// 연결 등록(종료 중이면 중단, 상한 초과면 거절)
+		switch s.track(conn) {
+		case errClosing:
+			_ = conn.Close()
+			return ErrServerClosed
+		case errMaxConns:
+			s.stats.rejectedMax.Add(1)
+			log.Printf("connection rejected remote=%s: %v", conn.RemoteAddr(), errMaxConns)
+			_ = conn.Close()
+			continue
+		case errMaxPerIP:
+			s.stats.rejectedPerIP.Add(1)
+			log.Printf("connection rejected remote=%s: %v", conn.RemoteAddr(), errMaxPerIP)
+			_ = conn.Close()
+			continue
+		}
+
 		// 연결은 고루틴 처리
-		go s.h.Handle(conn)
+		go func() {
+			defer s.untrack(conn)
+			s.h.Handle(conn)
+		}()
+	}
+}
+
+// 종료 중 여부
+func (s *Server) shuttingDown() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closing
+}
+
+// 연결 거절 사유
+var (
+	errClosing  = errors.New("server shutting down")
+	errMaxConns = errors.New("too many connections")
+	errMaxPerIP = errors.New("too many connections from this address")
+)
+
+// 연결 등록(상한 검사)
+func (s *Server) track(conn net.Conn) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closing {
+		return errClosing
+	}
+	if len(s.conns) >= s.cfg.MaxConns {
+		return errMaxConns
+	}
+	ip := hostOf(conn.RemoteAddr())
+	if s.perIP[ip] >= s.cfg.MaxConnsPerIP {
+		return errMaxPerIP
+	}
+	s.conns[conn] = struct{}{}
+	s.perIP[ip]++
+	s.wg.Add(1)
+	return nil
+}
+
+// 연결 해제
+func (s *Server) untrack(conn net.Conn) {
+	s.mu.Lock()
+	delete(s.conns, conn)
+	ip := hostOf(conn.RemoteAddr())
+	if s.perIP[ip]--; s.perIP[ip] <= 0 {
+		delete(s.perIP, ip)
+	}
+	s.mu.Unlock()
+	s.wg.Done()
+}
+
+// 주소에서 IP만
+func hostOf(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+// 정상 종료
+// - 리스너를 닫고 모든 연결에서 새 요청 수신 중단
+// - 처리중 요청은 응답까지 기다림
+// - ctx가 끝나면 처리중 요청 취소(자식 프로세스 kill) 후 연결을 닫고 ctx 에러 반환
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closing = true
+	ln := s.ln
+	s.mu.Unlock()
+
+	// 새 연결 중단
+	if ln != nil {
+		_ = ln.Close()
+	}
+
+	// 새 요청 중단
+	s.h.Drain()
+
+	// 남은 연결 대기
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	// 기한 초과: 실행 취소(취소 결과 응답은 잠시 기다림)
+	s.h.Abort()
+	select {
+	case <-done:
+		return ctx.Err()
+	case <-time.After(abortGrace):
 	}
+
+	// 그래도 남은 연결 강제 종료
+	s.mu.Lock()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+
+	// 정리 대기
+	<-done
+	return ctx.Err()
 }
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"sync/atomic"
+
+	"golang-network-labs/tcp/internal/handler"
+)
+
+// 연결 집계 스냅샷
+type Stats struct {
+	// 현재 연결 수
+	Conns int
+	// 동시 연결 상한으로 거절
+	RejectedMaxConns int64
+	// IP당 상한으로 거절
+	RejectedPerIP int64
+	// 첫 메시지 제한시간 초과
+	HeaderTimeouts int64
+	// 메시지 수신 제한시간 초과
+	ReadTimeouts int64
+	// 유휴 제한시간 초과
+	IdleTimeouts int64
+	// 응답 쓰기 제한시간 초과
+	WriteTimeouts int64
+	// 실행중/대기중 명령 수
+	ExecRunning int
+	ExecQueued  int
+}
+
+// 누적 카운터
+type counters struct {
+	rejectedMax   atomic.Int64
+	rejectedPerIP atomic.Int64
+	header        atomic.Int64
+	read          atomic.Int64
+	idle          atomic.Int64
+	write         atomic.Int64
+}
+
+// 제한시간 초과 집계(handler.OnTimeout)
+func (c *counters) timedOut(stage string) {
+	switch stage {
+	case handler.TimeoutHeader:
+		c.header.Add(1)
+	case handler.TimeoutRead:
+		c.read.Add(1)
+	case handler.TimeoutIdle:
+		c.idle.Add(1)
+	case handler.TimeoutWrite:
+		c.write.Add(1)
+	}
+}
+
+// 현재 집계
+func (s *Server) Stats() Stats {
+	s.mu.Lock()
+	conns := len(s.conns)
+	s.mu.Unlock()
+	running, queued := s.cfg.Exec.Pool.Load()
+
+	return Stats{
+		Conns:            conns,
+		RejectedMaxConns: s.stats.rejectedMax.Load(),
+		RejectedPerIP:    s.stats.rejectedPerIP.Load(),
+		HeaderTimeouts:   s.stats.header.Load(),
+		ReadTimeouts:     s.stats.read.Load(),
+		IdleTimeouts:     s.stats.idle.Load(),
+		WriteTimeouts:    s.stats.write.Load(),
+		ExecRunning:      running,
+		ExecQueued:       queued,
+	}
+}
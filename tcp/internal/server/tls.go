@@ -0,0 +1,60 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// TLS 설정(CertFile이 비면 평문)
+type TLSConfig struct {
+	// 서버 인증서
+	CertFile string
+	KeyFile  string
+	// 클라이언트 인증서 검증용 CA(설정 시 mTLS 강제)
+	ClientCAFile string
+	// 허용 클라이언트 CN(비면 CA 검증만)
+	AllowedClients []string
+}
+
+// TLS 사용 여부
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != ""
+}
+
+// 리스너용 tls.Config 생성
+func (c TLSConfig) build() (*tls.Config, error) {
+	if c.KeyFile == "" {
+		return nil, errors.New("tls: key file required with cert file")
+	}
+
+	// 서버 인증서 로드
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("tls: load key pair: %w", err)
+	}
+
+	tc := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	// 클라이언트 CA가 있으면 mTLS
+	if c.ClientCAFile != "" {
+		pem, err := os.ReadFile(c.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: read client ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("tls: no certificates in client ca file")
+		}
+		tc.ClientCAs = pool
+		tc.ClientAuth = tls.RequireAndVerifyClientCert
+	} else if len(c.AllowedClients) > 0 {
+		return nil, errors.New("tls: allowed clients need a client ca file")
+	}
+	return tc, nil
+}